@@ -0,0 +1,385 @@
+package main
+
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+	"example.com/seiscore-go/catalog"
+)
+
+
+var DATETIME_LAYOUTS = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02_15-04-05",
+}
+
+
+var INFO_COLUMNS = []string{
+	"PATH", "FORMAT", "FREQUENCY", "START", "STOP", "DURATION", "LONGITUDE", "LATITUDE",
+}
+
+
+type fileInfoJSON struct {
+	Path string `json:"path"`
+	FormatType string `json:"format_type"`
+	Channels []string `json:"channels"`
+	Frequency uint16 `json:"frequency"`
+	DatetimeStart string `json:"datetime_start"`
+	DatetimeStop string `json:"datetime_stop"`
+	Duration string `json:"duration"`
+	Longitude float64 `json:"longitude"`
+	Latitude float64 `json:"latitude"`
+}
+
+
+func formatDatetime(datetime time.Time) string {
+	return datetime.UTC().Format(time.RFC3339Nano)
+}
+
+
+func parseDatetime(value string) (time.Time, error) {
+	for _, layout := range DATETIME_LAYOUTS {
+		if datetime, err := time.Parse(layout, value); err == nil {
+			return datetime, nil
+		}
+	}
+	return time.Time{}, usageError{message: "invalid datetime " + value}
+}
+
+
+func parseComponents(value string) []string {
+	if len(value) == 0 {
+		return nil
+	}
+
+	if strings.Contains(value, ",") {
+		return strings.Split(value, ",")
+	}
+
+	components := []string{}
+	for _, component := range value {
+		components = append(components, string(component))
+	}
+	return components
+}
+
+
+func parseFlags(flags *flag.FlagSet, arguments []string, usage string) error {
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: seiscore %s %s\n", flags.Name(), usage)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(arguments); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{message: err.Error()}
+	}
+	return nil
+}
+
+
+func writeInfoTable(writer io.Writer, fileInfos []binaryfile.FileInfo) error {
+	tableWriter := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tableWriter, strings.Join(INFO_COLUMNS, "\t"))
+	for _, fileInfo := range fileInfos {
+		fmt.Fprintf(tableWriter, "%s\t%s\t%d\t%s\t%s\t%s\t%.6f\t%.6f\n",
+			fileInfo.Path, fileInfo.FormatType, fileInfo.Frequency,
+			formatDatetime(fileInfo.TimeStart), formatDatetime(fileInfo.TimeStop),
+			fileInfo.FormattedDuration(),
+			fileInfo.Coordinate.Longitude, fileInfo.Coordinate.Latitude)
+	}
+	return tableWriter.Flush()
+}
+
+
+func writeInfoJSON(writer io.Writer, fileInfos []binaryfile.FileInfo) error {
+	items := []fileInfoJSON{}
+	for _, fileInfo := range fileInfos {
+		items = append(items, fileInfoJSON{
+			Path: fileInfo.Path,
+			FormatType: fileInfo.FormatType,
+			Channels: fileInfo.Channels,
+			Frequency: fileInfo.Frequency,
+			DatetimeStart: formatDatetime(fileInfo.TimeStart),
+			DatetimeStop: formatDatetime(fileInfo.TimeStop),
+			Duration: fileInfo.FormattedDuration(),
+			Longitude: fileInfo.Coordinate.Longitude,
+			Latitude: fileInfo.Coordinate.Latitude})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+
+func readFileInfo(path string) (binaryfile.FileInfo, error) {
+	recording, err := binaryfile.OpenRecording(path)
+	if err != nil {
+		return binaryfile.FileInfo{}, err
+	}
+	defer recording.Close()
+	return recording.FileInfo()
+}
+
+
+func writeInfo(writer io.Writer, fileInfos []binaryfile.FileInfo, isJSON bool) error {
+	if isJSON {
+		return writeInfoJSON(writer, fileInfos)
+	}
+	return writeInfoTable(writer, fileInfos)
+}
+
+
+func runInfo(arguments []string) error {
+	flags := flag.NewFlagSet("info", flag.ContinueOnError)
+	isJSON := flags.Bool("json", false, "print JSON instead of a table")
+	if err := parseFlags(flags, arguments, "[-json] FILE..."); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return usageError{message: "no files given"}
+	}
+
+	var firstErr error
+	fileInfos := []binaryfile.FileInfo{}
+	for _, path := range flags.Args() {
+		fileInfo, err := readFileInfo(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "seiscore:", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		fileInfos = append(fileInfos, fileInfo)
+	}
+
+	if err := writeInfo(os.Stdout, fileInfos, *isJSON); err != nil {
+		return err
+	}
+
+	if firstErr != nil {
+		failedCount := flags.NArg() - len(fileInfos)
+		return fmt.Errorf("%d of %d files failed, first error: %w", failedCount, flags.NArg(), firstErr)
+	}
+	return nil
+}
+
+
+func createOutput(path string) (io.WriteCloser, error) {
+	if len(path) == 0 || path == "-" {
+		return os.Stdout, nil
+	}
+	return os.Create(path)
+}
+
+
+func writeStreamCSV(writer io.Writer, stream *binaryfile.SignalStream) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(append([]string{"datetime"}, stream.Channels()...)); err != nil {
+		return err
+	}
+
+	datetimeStart, frequency := stream.DatetimeStart(), stream.Frequency()
+	row := make([]string, len(stream.Channels()) + 1)
+	samplesCount := int64(0)
+	for stream.Next() {
+		block := stream.Block()
+		for i := 0; i < block.SamplesCount(); i++ {
+			datetime := datetimeStart.Add(binaryfile.SamplesDuration(samplesCount, frequency))
+			row[0] = formatDatetime(datetime)
+			for channel, signal := range block.Signals {
+				row[channel + 1] = strconv.FormatInt(int64(signal[i]), 10)
+			}
+
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+			samplesCount++
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return err
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+
+type exportOptions struct {
+	components string
+	resampleFrequency uint
+	isUseAvgValues bool
+	outputPath string
+}
+
+func (options *exportOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&options.components, "components", "", "components to export, e.g. ZXY or Z,X (default all)")
+	flags.UintVar(&options.resampleFrequency, "resample", 0, "resample frequency in Hz (default original)")
+	flags.BoolVar(&options.isUseAvgValues, "avg", false, "subtract channel averages")
+	flags.StringVar(&options.outputPath, "o", "", "output CSV file (default stdout)")
+}
+
+func (options exportOptions) binaryFile(path string) (binaryfile.BinaryFile, error) {
+	if options.resampleFrequency > 0xffff {
+		return binaryfile.BinaryFile{}, usageError{message: "resample frequency is too large"}
+	}
+
+	return binaryfile.BinaryFile{
+		Path: path,
+		ResampleFrequency: uint16(options.resampleFrequency),
+		IsUseAvgValues: options.isUseAvgValues}, nil
+}
+
+
+func exportCSV(recording *binaryfile.Recording, timeStart time.Time, timeStop time.Time, options exportOptions) error {
+	stream, err := recording.NewSignalStream(timeStart, timeStop, parseComponents(options.components), 0)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	output, err := createOutput(options.outputPath)
+	if err != nil {
+		return err
+	}
+
+	err = writeStreamCSV(output, stream)
+	if output != os.Stdout {
+		if closeErr := output.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+
+func openRecording(flags *flag.FlagSet, options exportOptions) (*binaryfile.Recording, error) {
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return nil, usageError{message: "exactly one file expected"}
+	}
+
+	binFile, err := options.binaryFile(flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	return binFile.Open()
+}
+
+
+func runCut(arguments []string) error {
+	flags := flag.NewFlagSet("cut", flag.ContinueOnError)
+	options := exportOptions{}
+	options.register(flags)
+	start := flags.String("start", "", "window start, e.g. 2022-01-20T08:00:00Z (required)")
+	stop := flags.String("stop", "", "window stop, e.g. 2022-01-20T09:00:00Z (required)")
+	if err := parseFlags(flags, arguments, "-start TIME -stop TIME [options] FILE"); err != nil {
+		return err
+	}
+
+	if len(*start) == 0 || len(*stop) == 0 {
+		flags.Usage()
+		return usageError{message: "-start and -stop are required"}
+	}
+
+	timeStart, err := parseDatetime(*start)
+	if err != nil {
+		return err
+	}
+
+	timeStop, err := parseDatetime(*stop)
+	if err != nil {
+		return err
+	}
+
+	recording, err := openRecording(flags, options)
+	if err != nil {
+		return err
+	}
+	defer recording.Close()
+
+	return exportCSV(recording, timeStart, timeStop, options)
+}
+
+
+func runExport(arguments []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	options := exportOptions{}
+	options.register(flags)
+	if err := parseFlags(flags, arguments, "[options] FILE"); err != nil {
+		return err
+	}
+
+	recording, err := openRecording(flags, options)
+	if err != nil {
+		return err
+	}
+	defer recording.Close()
+
+	timeStop, err := recording.DatetimeStop()
+	if err != nil {
+		return err
+	}
+	return exportCSV(recording, recording.DatetimeStart(), timeStop, options)
+}
+
+
+func runScan(arguments []string) error {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	isJSON := flags.Bool("json", false, "print JSON instead of a table")
+	indexPath := flags.String("index", "", "catalog index file (default " + catalog.DEFAULT_INDEX_NAME + " in the working directory)")
+	workersCount := flags.Int("workers", 0, "parallel readers (default number of CPUs)")
+	if err := parseFlags(flags, arguments, "[-json] [-index FILE] [-workers N] DIR"); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageError{message: "exactly one directory expected"}
+	}
+
+	root := flags.Arg(0)
+	if len(*indexPath) == 0 {
+		*indexPath = catalog.DEFAULT_INDEX_NAME
+	}
+
+	recordingsCatalog, err := catalog.OpenCatalogIndex(root, *indexPath)
+	if err != nil {
+		return err
+	}
+	recordingsCatalog.WorkersCount = *workersCount
+
+	result, err := recordingsCatalog.Scan(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, scanError := range result.Errors {
+		fmt.Fprintln(os.Stderr, "seiscore: scan:", scanError)
+	}
+	fmt.Fprintf(os.Stderr, "%d files: %d added, %d updated, %d removed, %d unchanged, %d failed, %d not recordings, %d skipped\n",
+		result.FilesCount, result.AddedCount, result.UpdatedCount, result.RemovedCount,
+		result.UnchangedCount, result.FailedCount, result.NotRecordingsCount, len(result.Errors))
+	return writeInfo(os.Stdout, recordingsCatalog.Query(catalog.Query{}), *isJSON)
+}
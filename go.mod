@@ -1,7 +1,7 @@
-module example.com/runner
+module example.com/seiscore
 
 go 1.18
 
-replace example.com/binaryfile => ./binaryfile
+replace example.com/seiscore-go => ./seiscore-go
 
-require example.com/binaryfile v0.0.0-00010101000000-000000000000
+require example.com/seiscore-go v0.0.0-00010101000000-000000000000
@@ -2,18 +2,103 @@ package main
 
 
 import (
-	"log"
-	"example.com/binaryfile"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"example.com/seiscore-go/binaryfile"
+	"example.com/seiscore-go/catalog"
 )
 
 
+const (
+	EXIT_OK = 0
+	EXIT_FAILURE = 1
+	EXIT_USAGE = 2
+	EXIT_BAD_PATH = 3
+	EXIT_BAD_DATA = 4
+	EXIT_BAD_PARAMETER = 5
+)
+
+
+const USAGE = `Usage: seiscore <command> [options] [arguments]
+
+Commands:
+  info    print recording info for one or many files
+  cut     extract a time window of a recording to CSV
+  export  export a whole recording to CSV
+  scan    list every recording under a directory tree
+
+Run "seiscore <command> -h" for command options.
+`
+
+
+type command struct {
+	name string
+	run func(arguments []string) error
+}
+
+
+var commands = []command{
+	{name: "info", run: runInfo},
+	{name: "cut", run: runCut},
+	{name: "export", run: runExport},
+	{name: "scan", run: runScan},
+}
+
+
+type usageError struct {
+	message string
+}
+
+func (customError usageError) Error() string {
+	return customError.message
+}
+
+
+func exitCode(err error) int {
+	var usage usageError
+	var invalidIndex catalog.InvalidIndex
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return EXIT_OK
+	case errors.As(err, &usage):
+		return EXIT_USAGE
+	case errors.Is(err, binaryfile.BadFilePath{}), errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrPermission):
+		return EXIT_BAD_PATH
+	case errors.Is(err, binaryfile.BadHeaderData{}), errors.Is(err, binaryfile.BadSignalData{}), errors.As(err, &invalidIndex):
+		return EXIT_BAD_DATA
+	case errors.Is(err, binaryfile.InvalidDatetimeValue{}), errors.Is(err, binaryfile.InvalidResampleFrequency{}), errors.Is(err, binaryfile.UnknownComponentName{}):
+		return EXIT_BAD_PARAMETER
+	default:
+		return EXIT_FAILURE
+	}
+}
+
+
+func run(arguments []string) error {
+	if len(arguments) == 0 {
+		fmt.Fprint(os.Stderr, USAGE)
+		return usageError{message: "no command given"}
+	}
+
+	for _, item := range commands {
+		if item.name == arguments[0] {
+			return item.run(arguments[1:])
+		}
+	}
+
+	fmt.Fprint(os.Stderr, USAGE)
+	return usageError{message: "unknown command " + arguments[0]}
+}
+
 
 func main() {
-	a := binaryfile.ReadBaikal8Header("/media/michael/Data/Projects/GraviSeismicComparation/Vibrostend/seismic/20-01-2022/K07_2022-01-20_08-21-05/K07_2022-01-20_08-21-05.xx")
-	log.Println(a)
-	b := binaryfile.ReadBaikal7Header("/media/michael/Data/Projects/HydroFracturing/DemkinskoeDeposit/Demkinskoe_4771/Binary/HF_0019_2019-08-16_08-31-08_90041_527.00")
-	log.Println(b)
-
-	c := binaryfile.ReadSigmaHeader("/media/michael/Data/Projects/GraviSeismicComparation/Vibrostend/seismic/19-01-2022/SigmaN011_2022-01-19_10-06-11.bin")
-    log.Println(c)
-}
\ No newline at end of file
+	err := run(os.Args[1:])
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, "seiscore:", err)
+	}
+	os.Exit(exitCode(err))
+}
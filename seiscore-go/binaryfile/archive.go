@@ -0,0 +1,361 @@
+package binaryfile
+
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+
+const (
+	ZIP_ARCHIVE, TAR_ARCHIVE, TAR_GZ_ARCHIVE = "zip", "tar", "tar.gz"
+	TAR_MAGIC_OFFSET = 257
+	ARCHIVE_MEMBER_SEPARATOR = "!"
+)
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+	gzipMagic = []byte{0x1f, 0x8b}
+	tarMagic = []byte("ustar")
+)
+
+
+type ArchiveMember struct {
+	Name string
+	Size int64
+	ModTime time.Time
+	FormatType string
+}
+
+
+type countingReader struct {
+	reader io.Reader
+	offset int64
+}
+
+func (counter *countingReader) Read(buffer []byte) (int, error) {
+	bytesCount, err := counter.reader.Read(buffer)
+	counter.offset += int64(bytesCount)
+	return bytesCount, err
+}
+
+
+type Archive struct {
+	path string
+	file *os.File
+	size int64
+	archiveType string
+	members []ArchiveMember
+	zipFiles map[string]*zip.File
+	tarOffsets map[string]int64
+}
+
+
+func detectArchiveType(reader io.ReaderAt) (string, error) {
+	magic := make([]byte, TAR_MAGIC_OFFSET + len(tarMagic))
+	bytesCount, err := reader.ReadAt(magic, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	magic = magic[:bytesCount]
+
+	switch {
+	case bytes.HasPrefix(magic, zipMagic):
+		return ZIP_ARCHIVE, nil
+	case bytes.HasPrefix(magic, gzipMagic):
+		return TAR_GZ_ARCHIVE, nil
+	case len(magic) == TAR_MAGIC_OFFSET + len(tarMagic) && bytes.Equal(magic[TAR_MAGIC_OFFSET:], tarMagic):
+		return TAR_ARCHIVE, nil
+	default:
+		return "", BadFilePath{message: "Unsupported archive format"}
+	}
+}
+
+
+func detectMemberFormat(header []byte, name string, size int64) (string, bool) {
+	detection, err := DetectFormat(bytes.NewReader(header), size, fileExtension(name))
+	if err != nil {
+		return "", false
+	}
+	return detection.FormatType, true
+}
+
+
+type memberFile struct {
+	*os.File
+}
+
+func (file memberFile) Close() error {
+	err := file.File.Close()
+	if removeErr := os.Remove(file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+
+// Compressed members have no random access, so they are unpacked into a
+// temporary file that is removed when the recording is closed.
+func readMemberData(reader io.Reader, size int64) (memberFile, error) {
+	file, err := os.CreateTemp("", "seiscore-member-*")
+	if err != nil {
+		return memberFile{}, err
+	}
+
+	member := memberFile{file}
+	if _, err := io.CopyN(member, reader, size); err != nil {
+		member.Close()
+		return memberFile{}, err
+	}
+	return member, nil
+}
+
+
+func OpenArchive(archivePath string) (*Archive, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fileError(err, archivePath)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fileError(err, archivePath)
+	}
+
+	archive := &Archive{path: archivePath, file: file, size: info.Size()}
+	archive.archiveType, err = detectArchiveType(file)
+	if err != nil {
+		file.Close()
+		return nil, withPath(err, archivePath)
+	}
+
+	if archive.archiveType == ZIP_ARCHIVE {
+		err = archive.listZip()
+	} else {
+		err = archive.listTar()
+	}
+	if err != nil {
+		file.Close()
+		return nil, withPath(err, archivePath)
+	}
+	return archive, nil
+}
+
+func (archive *Archive) memberPath(name string) string {
+	return archive.path + ARCHIVE_MEMBER_SEPARATOR + name
+}
+
+func (archive *Archive) listZip() error {
+	zipReader, err := zip.NewReader(archive.file, archive.size)
+	if err != nil {
+		return err
+	}
+
+	archive.zipFiles = map[string]*zip.File{}
+	for _, zipFile := range zipReader.File {
+		if zipFile.FileInfo().IsDir() {
+			continue
+		}
+
+		memberReader, err := zipFile.Open()
+		if err != nil {
+			return withPath(err, archive.memberPath(zipFile.Name))
+		}
+		header := make([]byte, MAIN_HEADER_SIZE)
+		bytesCount, err := io.ReadFull(memberReader, header)
+		memberReader.Close()
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return withPath(err, archive.memberPath(zipFile.Name))
+		}
+
+		size := int64(zipFile.UncompressedSize64)
+		formatType, isRecording := detectMemberFormat(header[:bytesCount], zipFile.Name, size)
+		if !isRecording {
+			continue
+		}
+
+		archive.zipFiles[zipFile.Name] = zipFile
+		archive.members = append(archive.members, ArchiveMember{
+			Name: zipFile.Name,
+			Size: size,
+			ModTime: zipFile.Modified,
+			FormatType: formatType})
+	}
+	return nil
+}
+
+func (archive *Archive) tarReader() (*tar.Reader, *countingReader, error) {
+	counter := &countingReader{reader: io.NewSectionReader(archive.file, 0, archive.size)}
+	if archive.archiveType == TAR_ARCHIVE {
+		return tar.NewReader(counter), counter, nil
+	}
+
+	gzipReader, err := gzip.NewReader(counter.reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tar.NewReader(gzipReader), nil, nil
+}
+
+func (archive *Archive) listTar() error {
+	tarReader, counter, err := archive.tarReader()
+	if err != nil {
+		return err
+	}
+
+	archive.tarOffsets = map[string]int64{}
+	for {
+		tarHeader, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if tarHeader.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		dataOffset := int64(0)
+		if counter != nil {
+			dataOffset = counter.offset
+		}
+
+		header := make([]byte, MAIN_HEADER_SIZE)
+		bytesCount, err := io.ReadFull(tarReader, header)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return withPath(err, archive.memberPath(tarHeader.Name))
+		}
+		formatType, isRecording := detectMemberFormat(header[:bytesCount], tarHeader.Name, tarHeader.Size)
+		if !isRecording {
+			continue
+		}
+
+		archive.tarOffsets[tarHeader.Name] = dataOffset
+		archive.members = append(archive.members, ArchiveMember{
+			Name: tarHeader.Name,
+			Size: tarHeader.Size,
+			ModTime: tarHeader.ModTime,
+			FormatType: formatType})
+	}
+}
+
+func (archive *Archive) Path() string {
+	return archive.path
+}
+
+func (archive *Archive) ArchiveType() string {
+	return archive.archiveType
+}
+
+func (archive *Archive) Members() []ArchiveMember {
+	return append([]ArchiveMember{}, archive.members...)
+}
+
+func (archive *Archive) member(name string) (ArchiveMember, bool) {
+	for _, member := range archive.members {
+		if member.Name == name {
+			return member, true
+		}
+	}
+	return ArchiveMember{}, false
+}
+
+func (archive *Archive) memberReader(member ArchiveMember) (io.ReaderAt, io.Closer, error) {
+	switch archive.archiveType {
+	case ZIP_ARCHIVE:
+		zipFile := archive.zipFiles[member.Name]
+		if zipFile.Method == zip.Store {
+			dataOffset, err := zipFile.DataOffset()
+			if err != nil {
+				return nil, nil, err
+			}
+			return io.NewSectionReader(archive.file, dataOffset, member.Size), nil, nil
+		}
+
+		memberReader, err := zipFile.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer memberReader.Close()
+
+		file, err := readMemberData(memberReader, member.Size)
+		if err != nil {
+			return nil, nil, err
+		}
+		return file, file, nil
+	case TAR_ARCHIVE:
+		return io.NewSectionReader(archive.file, archive.tarOffsets[member.Name], member.Size), nil, nil
+	default:
+		tarReader, _, err := archive.tarReader()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for {
+			tarHeader, err := tarReader.Next()
+			if err == io.EOF {
+				return nil, nil, BadFilePath{message: "Archive member not found"}
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			if tarHeader.Name == member.Name {
+				file, err := readMemberData(tarReader, member.Size)
+				if err != nil {
+					return nil, nil, err
+				}
+				return file, file, nil
+			}
+		}
+	}
+}
+
+func (archive *Archive) Open(binFile BinaryFile) (*Recording, error) {
+	member, isFound := archive.member(binFile.Path)
+	binFile.Path = archive.memberPath(binFile.Path)
+	if !isFound {
+		return nil, ReadError{Path: binFile.Path, Err: BadFilePath{message: "Archive member not found"}}
+	}
+
+	reader, closer, err := archive.memberReader(member)
+	if err != nil {
+		return nil, withPath(err, binFile.Path)
+	}
+
+	recording, err := binFile.OpenReader(reader, member.Size)
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, err
+	}
+	recording.closer = closer
+	return recording, nil
+}
+
+func (archive *Archive) OpenMember(name string) (*Recording, error) {
+	return archive.Open(BinaryFile{Path: name})
+}
+
+func (archive *Archive) Close() error {
+	return archive.file.Close()
+}
+
+
+func IsArchivePath(archivePath string) bool {
+	switch path.Ext(archivePath) {
+	case ".zip", ".tar", ".tgz", ".gz":
+		return true
+	default:
+		return false
+	}
+}
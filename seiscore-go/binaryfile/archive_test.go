@@ -0,0 +1,166 @@
+package binaryfile
+
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+
+func writeTestArchive(t *testing.T, memberName string) string {
+	directory := t.TempDir()
+	recordingPath := filepath.Join(directory, memberName)
+	if err := WriteBinaryFile(recordingPath, BAIKAL7_FMT, testHeader(), testSignals(3, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(recordingPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archivePath := filepath.Join(directory, "records.zip")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	memberWriter, err := zipWriter.Create(memberName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memberWriter.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+
+func TestArchiveMemberPaths(t *testing.T) {
+	archivePath := writeTestArchive(t, "record.00")
+	archive, err := OpenArchive(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	recording, err := archive.OpenMember("record.00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recording.Close()
+	if recording.Path() != archivePath + "!record.00" {
+		t.Errorf("Member recording path %s", recording.Path())
+	}
+
+	_, err = recording.ReadSignal(recording.DatetimeStart().Add(-1), recording.DatetimeStart().Add(1000), 'Z')
+	var readError ReadError
+	if !errors.As(err, &readError) || readError.Path != archivePath + "!record.00" {
+		t.Errorf("Member read error %v has no archive path", err)
+	}
+
+	_, err = archive.OpenMember("missing.00")
+	if !errors.As(err, &readError) || readError.Path != archivePath + "!missing.00" {
+		t.Errorf("Missing member error %v has no archive path", err)
+	}
+	if !errors.Is(err, BadFilePath{}) {
+		t.Errorf("Missing member error %v is not BadFilePath", err)
+	}
+}
+
+
+func writeTestTarGzArchive(t *testing.T, memberName string) string {
+	directory := t.TempDir()
+	recordingPath := filepath.Join(directory, memberName)
+	if err := WriteBinaryFile(recordingPath, BAIKAL7_FMT, testHeader(), testSignals(3, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(recordingPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archivePath := filepath.Join(directory, "records.tar.gz")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: memberName, Mode: 0644, Size: int64(len(data))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tarWriter.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+
+func TestCompressedMemberTemporaryFile(t *testing.T) {
+	for _, archivePath := range []string{writeTestArchive(t, "record.00"), writeTestTarGzArchive(t, "record.00")} {
+		temporaryDirectory := t.TempDir()
+		t.Setenv("TMPDIR", temporaryDirectory)
+
+		archive, err := OpenArchive(archivePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recording, err := archive.OpenMember("record.00")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		datetimeStop, err := recording.DatetimeStop()
+		if err != nil {
+			t.Fatal(err)
+		}
+		signal, err := recording.ReadSignal(recording.DatetimeStart(), datetimeStop, 'Z')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(signal, testSignals(3, 1000)[0]) {
+			t.Errorf("%s member signal differs from written one", archive.ArchiveType())
+		}
+
+		files, err := os.ReadDir(temporaryDirectory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Errorf("%s member is unpacked into %d temporary files, want 1", archive.ArchiveType(), len(files))
+		}
+
+		if err := recording.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := archive.Close(); err != nil {
+			t.Fatal(err)
+		}
+		files, err = os.ReadDir(temporaryDirectory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 0 {
+			t.Errorf("%s member left %d temporary files", archive.ArchiveType(), len(files))
+		}
+	}
+}
@@ -2,17 +2,13 @@ package binaryfile
 
 
 import (
+	"io"
 	"math"
 	"time"
 	"os"
-	"strings"
 	"fmt"
 	"strconv"
 	"path"
-	"encoding/binary"
-	"bytes"
-	"io"
-	"bufio"
 )
 
 
@@ -23,9 +19,18 @@ type Coordinate struct {
 
 
 type FileHeader struct {
-	frequency uint16
-	datetimeStart time.Time
-	coordinate Coordinate
+	ChannelsCount uint16
+	Frequency uint16
+	DatetimeStart time.Time
+	Coordinate Coordinate
+	StationName string
+	InstrumentSerial uint32
+	Version uint16
+	TestType uint16
+	Adc AdcSettings
+	Gps GpsInfo
+	TimeSync TimeSyncInfo
+	Channels []ChannelHeader
 }
 
 
@@ -41,6 +46,7 @@ func formatDuration(days int, hours int, minutes int, seconds int) string {
 type FileInfo struct {
 	Path string
 	FormatType string
+	Channels []string
 	Frequency uint16
 	TimeStart time.Time
 	TimeStop time.Time
@@ -55,7 +61,7 @@ func (fileInfo FileInfo) secondsDuration() float64 {
 	return fileInfo.TimeStop.Sub(fileInfo.TimeStart).Seconds()
 }
 
-func (fileInfo FileInfo) formattedDuration() string {
+func (fileInfo FileInfo) FormattedDuration() string {
 	diff := fileInfo.TimeStop.Sub(fileInfo.TimeStart)
 	daysDiff := int(diff.Hours() / 24)
 	hoursDiff := int(int(diff.Hours()) % 24)
@@ -71,6 +77,8 @@ const (
 	SIGMA_SECONDS_OFFSET = 2
 	COMPONENTS_ORDER = "ZXY"
 	BASE_MEMORY_BLOCK_SIZE = 12582912
+	BAIKAL7_TIME_TICKS_PER_SECOND = 256000000
+	MAIN_HEADER_SIZE, CHANNEL_HEADER_SIZE = 120, 72
 )
 
 var BINARY_FILE_FORMATS = map[string]string{
@@ -79,36 +87,32 @@ var BINARY_FILE_FORMATS = map[string]string{
 	SIGMA_FMT: SIGMA_EXTENSION,
 }
 
+var baikal7Epoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
 
 func truncate(num float64, precision uint8) float64 {
 	return math.Round(num * math.Pow(10, float64(precision))) / math.Pow(10, float64(precision))
 }
 
 
-func isBinaryFilePath(path string) bool {
-	_, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		return false
-	}
+func SamplesDuration(samplesCount int64, frequency uint16) time.Duration {
+	seconds := samplesCount / int64(frequency)
+	remainder := samplesCount % int64(frequency)
+	return time.Duration(seconds) * time.Second + time.Duration(remainder) * time.Second / time.Duration(frequency)
+}
 
-	splitPath := strings.Split(path, ".")
-	extension := splitPath[len(splitPath) - 1]
 
-	allowedExtensions := [3]string{BAIKAL7_EXTENSION, BAIKAL8_EXTENSION, SIGMA_EXTENSION}
-	for _, item := range allowedExtensions {
-		if item == extension {
-			return true
-		}
-	}
-	return false
+func isBinaryFilePath(path string) bool {
+	_, err := DetectFileFormat(path)
+	return err == nil
 }
 
 
 func getDatetimeStartBaikal7(timeBegin uint64) time.Time {
-	seconds := timeBegin / 256000000
-	nanoseconds := int(float64(timeBegin % 256000000) * math.Pow(10, 9))
+	seconds := timeBegin / BAIKAL7_TIME_TICKS_PER_SECOND
+	nanoseconds := int(float64(timeBegin % BAIKAL7_TIME_TICKS_PER_SECOND) * math.Pow(10, 9) / BAIKAL7_TIME_TICKS_PER_SECOND)
 
-	datetimeStart := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	datetimeStart := baikal7Epoch
 	datetimeStart = datetimeStart.Add(time.Second * time.Duration(seconds))
 	datetimeStart = datetimeStart.Add(time.Nanosecond * time.Duration(nanoseconds))
 	return datetimeStart
@@ -170,20 +174,36 @@ func getCoordinatesSigma(longitudeLine string, latitudeLine string) (Coordinate,
 		return Coordinate{}, BadHeaderData{"Invalid longitude in header"}
 	}
 
+	if len(latitudeLine) != 8 {
+		return Coordinate{}, BadHeaderData{"Invalid latitude in header"}
+	}
+
 	latitudeSymbol := latitudeLine[len(latitudeLine) - 1]
 	if latitudeSymbol != 'N' && latitudeSymbol != 'S' {
 		return Coordinate{}, BadHeaderData{"Invalid latitude in header"}
 	}
 
-	integerPart, _ := strconv.ParseFloat(longitudeLine[:3], 64)
-	decimalPart, _ := strconv.ParseFloat(longitudeLine[3:len(longitudeLine) - 1], 64)
+	integerPart, err := strconv.ParseFloat(longitudeLine[:3], 64)
+	if err != nil {
+		return Coordinate{}, BadHeaderData{"Invalid longitude in header"}
+	}
+	decimalPart, err := strconv.ParseFloat(longitudeLine[3:len(longitudeLine) - 1], 64)
+	if err != nil {
+		return Coordinate{}, BadHeaderData{"Invalid longitude in header"}
+	}
 	longitude := truncate(integerPart + decimalPart / 60, 5)
 	if longitudeSymbol == 'W' {
 		longitude = -longitude
 	}
 
-	integerPart, _ = strconv.ParseFloat(latitudeLine[:2], 64)
-	decimalPart, _ = strconv.ParseFloat(latitudeLine[2:len(latitudeLine) - 1], 64)
+	integerPart, err = strconv.ParseFloat(latitudeLine[:2], 64)
+	if err != nil {
+		return Coordinate{}, BadHeaderData{"Invalid latitude in header"}
+	}
+	decimalPart, err = strconv.ParseFloat(latitudeLine[2:len(latitudeLine) - 1], 64)
+	if err != nil {
+		return Coordinate{}, BadHeaderData{"Invalid latitude in header"}
+	}
 	latitude := truncate(integerPart + decimalPart / 60, 5)
 	if latitudeSymbol == 'S' {
 		latitude = -latitude
@@ -192,43 +212,57 @@ func getCoordinatesSigma(longitudeLine string, latitudeLine string) (Coordinate,
 }
 
 
-func ReadBaikal7Header(path string) (FileHeader, error) {
-	file, _ := os.Open(path)
-	defer file.Close()
-
-	channelsCount := UnsignedShortType{file, 0, 1}.convertToNumber()
-	if int(channelsCount) != len(COMPONENTS_ORDER) {
-		return FileHeader{}, BadHeaderData{message: "Invalid channels count"}
+func readBaikal7Header(reader io.ReaderAt) (FileHeader, error) {
+	fields := &fieldReader{reader: reader}
+	channelsCount := fields.unsignedShort("channels count", 0)
+	if fields.err == nil && !isPlausibleChannelsCount(channelsCount) {
+		fields.fail("channels count", 0, BadHeaderData{message: "Invalid channels count"})
 	}
 
-	frequency := UnsignedShortType{file, 22, 1}.convertToNumber()
+	frequency := fields.unsignedShort("frequency", 22)
 
-	srcCoords := DoubleType{file, 72, 2}.convertToArray()
+	srcCoords := fields.doubles("coordinates", 72, 2)
 	latitude, longitude := truncate(srcCoords[0], 5), truncate(srcCoords[1], 5)
-	timeBegin := LongType{file, 104, 1}.convertToNumber()
+	timeBegin := fields.long("time begin", 104)
+	if fields.err != nil {
+		return FileHeader{}, fields.err
+	}
+
 	datetimeStart := getDatetimeStartBaikal7(timeBegin)
-	return FileHeader{
-		frequency: frequency, 
-		datetimeStart: datetimeStart, 
-		coordinate: Coordinate{
+	header := FileHeader{
+		ChannelsCount: channelsCount, 
+		Frequency: frequency, 
+		DatetimeStart: datetimeStart, 
+		Coordinate: Coordinate{
 			Longitude: longitude, 
-			Latitude: latitude}}, nil
+			Latitude: latitude}}
+	if err := readBaikalHeaderFields(reader, &header); err != nil {
+		return FileHeader{}, err
+	}
+	return header, nil
 }
 
 
-func ReadBaikal8Header(path string) (FileHeader, error) {
-	file, _ := os.Open(path)
-	defer file.Close()
+func readBaikal8Header(reader io.ReaderAt) (FileHeader, error) {
+	fields := &fieldReader{reader: reader}
+	channelsCount := fields.unsignedShort("channels count", 0)
+	if fields.err == nil && !isPlausibleChannelsCount(channelsCount) {
+		fields.fail("channels count", 0, BadHeaderData{message: "Invalid channels count"})
+	}
+
+	dateSrc := fields.unsignedShorts("date", 6, 3)
 
-	channelsCount := UnsignedShortType{file, 0, 1}.convertToNumber()
-	if int(channelsCount) != len(COMPONENTS_ORDER) {
-		return FileHeader{}, BadHeaderData{message: "Invalid channels count"}
+	srcVals := fields.doubles("sample interval", 48, 2)
+	if fields.err == nil && srcVals[0] <= 0 {
+		fields.fail("sample interval", 48, BadHeaderData{message: "Invalid sample interval"})
 	}
 
-	dateSrc := UnsignedShortType{file, 6, 3}.convertToArray()
+	srcCoords := fields.doubles("coordinates", 72, 2)
+	if fields.err != nil {
+		return FileHeader{}, fields.err
+	}
 
-	srcVals := DoubleType{file, 48, 2}.convertToArray()
-	frequency := uint16(1 / srcVals[0])
+	frequency := uint16(math.Round(1 / srcVals[0]))
 	seconds := int(srcVals[1])
 	nanoseconds := int((srcVals[1] - float64(seconds)) * math.Pow(10, 9))
 
@@ -238,408 +272,248 @@ func ReadBaikal8Header(path string) (FileHeader, error) {
 	datetimeStart = datetimeStart.Add(time.Second * time.Duration(seconds))
 	datetimeStart = datetimeStart.Add(time.Nanosecond * time.Duration(nanoseconds))
 	
-	srcCoords := DoubleType{file, 72, 2}.convertToArray()
 	latitude, longitude := truncate(srcCoords[0], 5), truncate(srcCoords[1], 5)
-	return FileHeader{
-		frequency: frequency, 
-		datetimeStart: datetimeStart, 
-		coordinate: Coordinate{
+	header := FileHeader{
+		ChannelsCount: channelsCount, 
+		Frequency: frequency, 
+		DatetimeStart: datetimeStart, 
+		Coordinate: Coordinate{
 			Longitude: longitude, 
-			Latitude: latitude}}, nil 
+			Latitude: latitude}}
+	if err := readBaikalHeaderFields(reader, &header); err != nil {
+		return FileHeader{}, err
+	}
+	return header, nil 
 }
 
 
-func ReadSigmaHeader(path string) (FileHeader, error) {
-	file, _ := os.Open(path)
-	defer file.Close()
+func readSigmaHeader(reader io.ReaderAt) (FileHeader, error) {
+	fields := &fieldReader{reader: reader}
+	channelsCount := fields.unsignedShort("channels count", 12)
+	if fields.err == nil && !isPlausibleChannelsCount(channelsCount) {
+		fields.fail("channels count", 12, BadHeaderData{message: "Invalid channels count"})
+	}
 
-	channelsCount := UnsignedShortType{file, 12, 1}.convertToNumber()
-	if int(channelsCount) != len(COMPONENTS_ORDER) {
-		return FileHeader{}, BadHeaderData{message: "Invalid channels count"}
+	frequency := fields.unsignedShort("frequency", 24)
+	latitudeSrc, longitudeSrc := fields.chars("latitude", 40, 8), fields.chars("longitude", 48, 9)
+	datetimeSrc := fields.unsignedInts("datetime", 60, 2)
+	if fields.err != nil {
+		return FileHeader{}, fields.err
 	}
 
-	frequency := UnsignedShortType{file, 24, 1}.convertToNumber()
-	latitudeSrc, longitudeSrc := CharType{file, 40, 8}.convert(), CharType{file, 48, 9}.convert()
 	coordinates, err := getCoordinatesSigma(longitudeSrc, latitudeSrc)
 	if err != nil {
-		return FileHeader{}, err
+		return FileHeader{}, ReadError{Offset: 40, Field: "coordinates", Err: err}
 	}
 
-	datetimeSrc := UnsignedIntType{file, 60, 2}.convertToArray()
 	datetimeStart, err := getDatetimeStartSigma(datetimeSrc[0], datetimeSrc[1])
 	if err != nil {
-		return FileHeader{}, err
+		return FileHeader{}, ReadError{Offset: 60, Field: "datetime", Err: err}
 	}
 	
-	return FileHeader{
-		frequency: frequency, 
-		datetimeStart: datetimeStart, 
-		coordinate: coordinates} , nil
-}
-
-
-type BinaryFile struct {
-	Path string
-	ResampleFrequency uint16
-	IsUseAvgValues bool
-}
-
-func (binFile BinaryFile) FileExtension() (string, error) {
-	if len(binFile.Path) == 0 {
-		return "", BadFilePath{message: "Empty file path"}
-	}
-	splitPath := strings.Split(binFile.Path, ".")
-	return splitPath[len(splitPath) - 1], nil
-}
-
-func (binFile BinaryFile) FormatType() (string, error) {
-	currentExtension, err := binFile.FileExtension()
-	if err != nil {
-		return "", err
-	}
-
-	for fileFormat, fileExtension := range BINARY_FILE_FORMATS {
-		if currentExtension == fileExtension {
-			return fileFormat, nil
-		}
+	header := FileHeader{
+		ChannelsCount: channelsCount, 
+		Frequency: frequency, 
+		DatetimeStart: datetimeStart, 
+		Coordinate: coordinates}
+	if err := readSigmaHeaderFields(reader, &header); err != nil {
+		return FileHeader{}, err
 	}
-	return "", BadFilePath{message: "Invalid file format"}
+	return header, nil
 }
 
-func (binFile BinaryFile) fileHeader() (FileHeader, error) {
-	defaultValue := FileHeader{}
-	formatType, err := binFile.FormatType()
-	if err != nil {
-		return defaultValue, err
-	}
 
+func readHeader(reader io.ReaderAt, formatType string) (FileHeader, error) {
 	switch formatType {
 	case BAIKAL7_FMT:
-		header, err := ReadBaikal7Header(binFile.Path)
-		if err != nil {
-			return defaultValue, err
-		}
-		return header, nil
+		return readBaikal7Header(reader)
 	case BAIKAL8_FMT:
-		header, err := ReadBaikal8Header(binFile.Path)
-		if err != nil {
-			return defaultValue, err
-		}
-		return header, nil
+		return readBaikal8Header(reader)
 	case SIGMA_FMT:
-		header, err := ReadSigmaHeader(binFile.Path)
-		if err != nil {
-			return defaultValue, err
-		}
-		return header, nil
+		return readSigmaHeader(reader)
 	default:
-		return defaultValue, BadFilePath{message: "Unknown format type"}
+		return FileHeader{}, BadFilePath{message: "Unknown format type"}
 	}
 }
 
-func (binFile BinaryFile) GetResampleFrequency() (uint16, error) {
-	header, err := binFile.fileHeader()
-	if err != nil {
-		return 0, err
-	}
 
-	switch freq := binFile.ResampleFrequency; {
-	case freq < 0:
-		return 0, InvalidResampleFrequency{message: fmt.Sprint(freq)}
-	case freq == 0:
-		return header.frequency, nil
-	case header.frequency % freq == 0:
-		return freq, nil
-	default:
-		return 0, InvalidResampleFrequency{message: fmt.Sprint(freq)}
+func ReadHeader(reader io.ReaderAt, size int64, formatType string) (FileHeader, error) {
+	if size < MAIN_HEADER_SIZE {
+		return FileHeader{}, BadHeaderData{message: "File is shorter than main header"}
 	}
-}
-
-func (binFile BinaryFile) headerMemorySize() uint16 {
-	return uint16(120 + 72 * len(COMPONENTS_ORDER))
-}
 
-func (binFile BinaryFile) discreteCount() (uint64, error) {
-	info, err := os.Stat(binFile.Path)
+	header, err := readHeader(reader, formatType)
 	if err != nil {
-		return 0, err
+		return FileHeader{}, err
 	}
 
-	headerSize := binFile.headerMemorySize()
-
-	size := info.Size()
-	discreteCount := (uint64(size) - uint64(headerSize)) / (uint64(len(COMPONENTS_ORDER)) * uint64(UnsignedIntType{}.ByteSize()))
-	return discreteCount, nil
-}
-
-func (binFile BinaryFile) secondsDuration() (float64, error) {
-	header, err := binFile.fileHeader()
-	if err != nil {
-		return 0, err
+	if header.Frequency == 0 {
+		return FileHeader{}, BadHeaderData{message: "Zero sampling frequency"}
 	}
-
-	discreteCount, err := binFile.discreteCount()
-	if err != nil {
-		return 0, err
+	if size < int64(headerMemorySize(int(header.ChannelsCount))) {
+		return FileHeader{}, BadHeaderData{message: "File is shorter than header"}
 	}
-
-	frequency := header.frequency
-	accuracy := uint8(math.Log10(float64(frequency)))
-
-	deltaSeconds := truncate(float64(discreteCount) / float64(frequency), accuracy)
-	return deltaSeconds, nil
+	return header, nil
 }
 
-func (binFile BinaryFile) DatetimeStart() (time.Time, error) {
-	formatType, err := binFile.FormatType()
+
+func readHeaderFile(path string, formatType string) (FileHeader, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return time.Time{}, err
+		return FileHeader{}, fileError(err, path)
 	}
+	defer file.Close()
 
-	header, err := binFile.fileHeader()
+	info, err := file.Stat()
 	if err != nil {
-		return time.Time{}, err
+		return FileHeader{}, fileError(err, path)
 	}
 
-	offset := 0
-	if formatType == SIGMA_FMT {
-		offset = SIGMA_SECONDS_OFFSET
-	}
-	return header.datetimeStart.Add(time.Second * time.Duration(offset)), nil
+	header, err := ReadHeader(file, info.Size(), formatType)
+	return header, withPath(err, path)
 }
 
-func (binFile BinaryFile) DatetimeStop() (time.Time, error) {
-	datetimeStart, err := binFile.DatetimeStart()
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	secondsDuration, err := binFile.secondsDuration()
-	if err != nil {
-		return time.Time{}, err
-	}
 
-	nanosecondsDuration := secondsDuration * 1e9
-	return datetimeStart.Add(time.Nanosecond * time.Duration(nanosecondsDuration)), nil
+func ReadBaikal7Header(path string) (FileHeader, error) {
+	return readHeaderFile(path, BAIKAL7_FMT)
 }
 
-func (binFile BinaryFile) FileInfo() (FileInfo, error) {
-	defaultValue := FileInfo{}
 
-	formatType, err := binFile.FormatType()
-	if err != nil {
-		return defaultValue, err
-	}
+func ReadBaikal8Header(path string) (FileHeader, error) {
+	return readHeaderFile(path, BAIKAL8_FMT)
+}
 
-	path := binFile.Path
 
-	header, err := binFile.fileHeader()
-	if err != nil {
-		return defaultValue, err
-	}
-	timeStart, _ := binFile.DatetimeStart()
-	timeStop, err := binFile.DatetimeStop()
-	if err != nil {
-		return defaultValue, err
-	}
-	
-	return FileInfo{
-		Path: path, 
-		FormatType: formatType, 
-		Frequency: header.frequency, 
-		TimeStart: timeStart, 
-		TimeStop: timeStop, Coordinate: header.coordinate}, nil
-	
+func ReadSigmaHeader(path string) (FileHeader, error) {
+	return readHeaderFile(path, SIGMA_FMT)
 }
 
-func (binFile BinaryFile) IsGoodReadDatetimeStart(datetime time.Time) (bool, error) {
-	datetimeStart, err := binFile.DatetimeStart()
-	if err != nil {
-		return false, err
-	}
 
-	datetimeStop, err := binFile.DatetimeStop()
-	if err != nil {
-		return false, err
-	}
-
-	secondsDiff := datetime.Sub(datetimeStart).Seconds()
-	if secondsDiff < 0 {
-		return false, InvalidDatetimeValue{message: "Reading time start is less than recording time start"}
-	}
+// BinaryFile methods open the file for each call. To make several calls on
+// one file, Open it once and use the Recording methods.
+type BinaryFile struct {
+	Path string
+	ResampleFrequency uint16
+	IsUseAvgValues bool
+}
 
-	secondsDiff = datetimeStop.Sub(datetime).Seconds()
-	if secondsDiff <= 0 {
-		return false, InvalidDatetimeValue{message: "Reading time start is more than recording time stop"}
+func (binFile BinaryFile) FileExtension() (string, error) {
+	if len(binFile.Path) == 0 {
+		return "", BadFilePath{message: "Empty file path"}
 	}
-
-	return true, nil
+	return fileExtension(binFile.Path), nil
 }
 
-func (binFile BinaryFile) IsGoodReadDatetimeStop(datetime time.Time) (bool, error) {
-	datetimeStart, err := binFile.DatetimeStart()
+// Deprecated: opens the file on every call, use Open and Recording.FormatType.
+func (binFile BinaryFile) FormatType() (string, error) {
+	recording, err := binFile.Open()
 	if err != nil {
-		return false, err
+		return "", err
 	}
+	defer recording.Close()
+	return recording.FormatType(), nil
+}
 
-	datetimeStop, err := binFile.DatetimeStop()
+func (binFile BinaryFile) fileHeader() (FileHeader, error) {
+	recording, err := binFile.Open()
 	if err != nil {
-		return false, err
-	}
-
-	secondsDiff := datetime.Sub(datetimeStart).Seconds()
-	if secondsDiff <= 0 {
-		return false, InvalidDatetimeValue{message: "Reading time stop is less than recording time start"}
-	}
-
-	secondsDiff = datetimeStop.Sub(datetime).Seconds()
-	if secondsDiff < 0 {
-		return false, InvalidDatetimeValue{message: "Reading time stop is more than recording time stop"}
+		return FileHeader{}, err
 	}
+	defer recording.Close()
+	return recording.Header(), nil
+}
 
-	return true, nil
+// Deprecated: opens the file on every call, use Open and Recording.Header.
+func (binFile BinaryFile) Header() (FileHeader, error) {
+	return binFile.fileHeader()
 }
 
-func (binFile BinaryFile) resampleParameter() (uint16, error) {
-	resampleFrequency, err := binFile.GetResampleFrequency()
+// Deprecated: opens the file on every call, use Open and Recording.GetResampleFrequency.
+func (binFile BinaryFile) GetResampleFrequency() (uint16, error) {
+	recording, err := binFile.Open()
 	if err != nil {
 		return 0, err
 	}
-
-	header, _  := binFile.fileHeader()
-	return header.frequency / resampleFrequency, nil
+	defer recording.Close()
+	return recording.GetResampleFrequency()
 }
 
-func (binFile BinaryFile) componentIndex(component rune) (uint8, error) {
-	for i, sourceComponent := range COMPONENTS_ORDER {
-		if sourceComponent == component {
-			return uint8(i), nil
-		}
-	}
-	return 0, UnknownComponentName{message: string(component)}
+func headerMemorySize(channelsCount int) int {
+	return MAIN_HEADER_SIZE + CHANNEL_HEADER_SIZE * channelsCount
 }
 
-func (binFile BinaryFile) getIndexesInterval(datetimeStart time.Time, datetimeStop time.Time) ([2]uint64, error) {
-	defaultValue := [2]uint64{0, 0}
-	_, err := binFile.IsGoodReadDatetimeStart(datetimeStart)
+// Deprecated: opens the file on every call, use Open and Recording.ChannelNames.
+func (binFile BinaryFile) ChannelNames() ([]string, error) {
+	header, err := binFile.fileHeader()
 	if err != nil {
-		return defaultValue, err
+		return nil, err
 	}
+	return header.ChannelNames(), nil
+}
 
-	_, err = binFile.IsGoodReadDatetimeStop(datetimeStop)
+// Deprecated: opens the file on every call, use Open and Recording.DatetimeStart.
+func (binFile BinaryFile) DatetimeStart() (time.Time, error) {
+	recording, err := binFile.Open()
 	if err != nil {
-		return defaultValue, err
+		return time.Time{}, err
 	}
+	defer recording.Close()
+	return recording.DatetimeStart(), nil
+}
 
-	resampleParameter, err := binFile.resampleParameter()
+// Deprecated: opens the file on every call, use Open and Recording.DatetimeStop.
+func (binFile BinaryFile) DatetimeStop() (time.Time, error) {
+	recording, err := binFile.Open()
 	if err != nil {
-		return defaultValue, err
+		return time.Time{}, err
 	}
-
-	header, _ := binFile.fileHeader()
-	originFrequency := header.frequency
-
-	recordingDatetimeStart, _ := binFile.DatetimeStart()
-	
-	secondsDiff := datetimeStart.Sub(recordingDatetimeStart).Seconds()
-	startIndex := uint64(math.Round(secondsDiff * float64(originFrequency)))
-
-	secondsDiff = datetimeStop.Sub(recordingDatetimeStart).Seconds()
-	stopIndex := uint64(math.Round(secondsDiff * float64(originFrequency)))
-
-	signalLength := (stopIndex - startIndex) / uint64(resampleParameter)
-	stopIndex = startIndex + signalLength * uint64(resampleParameter)
-
-	return [2]uint64{startIndex, stopIndex}, nil
+	defer recording.Close()
+	return recording.DatetimeStop()
 }
 
-func (binFile BinaryFile) readSignal(timeStart time.Time, timeStop time.Time, component rune) ([]int32, error) {
-	signal := []int32{}
-	indexes, err := binFile.getIndexesInterval(timeStart, timeStop)
+// Deprecated: opens the file on every call, use Open and Recording.SamplesCount.
+func (binFile BinaryFile) SamplesCount() (uint64, error) {
+	recording, err := binFile.Open()
 	if err != nil {
-		return signal, err
+		return 0, err
 	}
+	defer recording.Close()
+	return recording.SamplesCount()
+}
 
-	columnIndex, err := binFile.componentIndex(component)
+// Deprecated: opens the file on every call, use Open and Recording.FileInfo.
+func (binFile BinaryFile) FileInfo() (FileInfo, error) {
+	recording, err := binFile.Open()
 	if err != nil {
-		return signal, err
+		return FileInfo{}, err
 	}
+	defer recording.Close()
+	return recording.FileInfo()
+}
 
-	resampleParameter, err := binFile.resampleParameter()
+// Deprecated: opens the file on every call, use Open and Recording.IsGoodReadDatetimeStart.
+func (binFile BinaryFile) IsGoodReadDatetimeStart(datetime time.Time) (bool, error) {
+	recording, err := binFile.Open()
 	if err != nil {
-		return signal, err
+		return false, err
 	}
-
-	channelsCount := len(COMPONENTS_ORDER)
-	oneRecordBytesSize :=  4 * channelsCount
-
-	skippingBlock := int(indexes[0]) * oneRecordBytesSize
-	offsetSize := int(binFile.headerMemorySize()) + skippingBlock
-
-	signalBytesSize := int(indexes[1] - indexes[0]) * oneRecordBytesSize
-
-	file, _ := os.Open(binFile.Path)
-	defer file.Close()
-
-	file.Seek(int64(offsetSize), 0)
-
-	reader := bufio.NewReader(file)
-	buffer := make([]byte, BASE_MEMORY_BLOCK_SIZE)
-
-	readBytesCount := 0
-	partSignal := make([]int32, resampleParameter)
-	currentPosition := 0
-	for readBytesCount < signalBytesSize {
-		bytesCount, err := reader.Read(buffer[:cap(buffer)])
-		buffer = buffer[:bytesCount]
-		if bytesCount == 0 {
-			if err == nil {
-				continue
-			}
-
-			if err == io.EOF {
-				break
-			}
-			return []int32{}, BadSignalData{message: "Unexpected EOF"}
-		} else {
-			if err != nil {
-				return []int32{}, BadSignalData{message: "Unexpected EOF"}
-			}
-		}
-
-		readBytesCount += bytesCount
-
-		recordsCount := bytesCount / oneRecordBytesSize
-		componentMeasures := make([]int32, recordsCount * channelsCount)
-		convertBuffer := bytes.NewBuffer(buffer)
-		binary.Read(convertBuffer, binary.LittleEndian, &componentMeasures)
-
-		for i := 0; i < recordsCount; i++ {
-			index := 3 * i + int(columnIndex)
-			partSignal[currentPosition] = componentMeasures[index]
-			currentPosition++
-
-			if currentPosition == int(resampleParameter) {
-				currentPosition = 0
-				sumValue := 0
-				for j := 0; j < int(resampleParameter); j++ {
-					sumValue += int(partSignal[j])
-				}
-				signal = append(signal, int32(sumValue / int(resampleParameter)))
-			}
-		}	
-	}
-	return signal, nil
+	defer recording.Close()
+	return recording.IsGoodReadDatetimeStart(datetime)
 }
 
-func (binFile BinaryFile) ReadSignal(timeStart time.Time, timeStop time.Time, component rune) ([]int32, error) {
-	signal, err := binFile.readSignal(timeStart, timeStop, component)
+// Deprecated: opens the file on every call, use Open and Recording.IsGoodReadDatetimeStop.
+func (binFile BinaryFile) IsGoodReadDatetimeStop(datetime time.Time) (bool, error) {
+	recording, err := binFile.Open()
 	if err != nil {
-		return signal, nil
+		return false, err
 	}
+	defer recording.Close()
+	return recording.IsGoodReadDatetimeStop(datetime)
+}
 
-	if !binFile.IsUseAvgValues {
-		return signal, nil
+func removeAverage(signal []int32) {
+	if len(signal) == 0 {
+		return
 	}
 
 	var totalSum int64
@@ -652,6 +526,35 @@ func (binFile BinaryFile) ReadSignal(timeStart time.Time, timeStop time.Time, co
 	for i := 0; i < len(signal); i++ {
 		signal[i] -= average
 	}
+}
+
+func (binFile BinaryFile) ReadChannel(timeStart time.Time, timeStop time.Time, channelIndex int) ([]int32, error) {
+	recording, err := binFile.Open()
+	if err != nil {
+		return []int32{}, err
+	}
+	defer recording.Close()
+	return recording.ReadChannel(timeStart, timeStop, channelIndex)
+}
+
+func (binFile BinaryFile) ReadChannelByName(timeStart time.Time, timeStop time.Time, channelName string) ([]int32, error) {
+	recording, err := binFile.Open()
+	if err != nil {
+		return []int32{}, err
+	}
+	defer recording.Close()
+	return recording.ReadChannelByName(timeStart, timeStop, channelName)
+}
+
+func (binFile BinaryFile) ReadSignal(timeStart time.Time, timeStop time.Time, component rune) ([]int32, error) {
+	return binFile.ReadChannelByName(timeStart, timeStop, string(component))
+}
 
-	return signal, nil
+func (binFile BinaryFile) ReadSignals(timeStart time.Time, timeStop time.Time, components []string) (MultichannelTrace, error) {
+	recording, err := binFile.Open()
+	if err != nil {
+		return MultichannelTrace{}, err
+	}
+	defer recording.Close()
+	return recording.ReadSignals(timeStart, timeStop, components)
 }
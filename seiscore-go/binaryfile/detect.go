@@ -0,0 +1,252 @@
+package binaryfile
+
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+
+const (
+	MAX_CHANNELS_COUNT = 64
+	MAX_FREQUENCY = 20000
+	MIN_DETECTION_CONFIDENCE = 0.5
+)
+
+
+type FormatDetection struct {
+	FormatType string
+	Confidence float64
+}
+
+
+type detectionScore struct {
+	score float64
+	total float64
+}
+
+func (detection *detectionScore) check(isPassed bool, weight float64) {
+	detection.total += weight
+	if isPassed {
+		detection.score += weight
+	}
+}
+
+
+func formatTypeByExtension(extension string) string {
+	for fileFormat, fileExtension := range BINARY_FILE_FORMATS {
+		if extension == fileExtension {
+			return fileFormat
+		}
+	}
+
+	if len(extension) == 2 {
+		if _, err := strconv.Atoi(extension); err == nil {
+			return BAIKAL7_FMT
+		}
+	}
+	return ""
+}
+
+
+func fileExtension(path string) string {
+	splitPath := strings.Split(path, ".")
+	return splitPath[len(splitPath) - 1]
+}
+
+
+func isPlausibleChannelsCount(channelsCount uint16) bool {
+	return channelsCount > 0 && channelsCount <= MAX_CHANNELS_COUNT
+}
+
+
+func isPlausibleFrequency(frequency float64) bool {
+	return frequency >= 1 && frequency <= MAX_FREQUENCY
+}
+
+
+func isPlausibleDatetime(datetime time.Time) bool {
+	return !datetime.Before(baikal7Epoch) && datetime.Year() < 2100
+}
+
+
+func isPlausibleCoordinate(latitude float64, longitude float64) bool {
+	if math.IsNaN(latitude) || math.IsNaN(longitude) {
+		return false
+	}
+	return math.Abs(latitude) <= 90 && math.Abs(longitude) <= 180
+}
+
+
+func isWholeRecordsSize(size int64, channelsCount uint16) bool {
+	headerSize := int64(headerMemorySize(int(channelsCount)))
+	if size < headerSize {
+		return false
+	}
+	return (size - headerSize) % (4 * int64(channelsCount)) == 0
+}
+
+
+func scoreBaikalCommon(header []byte, size int64, detection *detectionScore) bool {
+	channelsCount := binary.LittleEndian.Uint16(header[0:])
+	if !isPlausibleChannelsCount(channelsCount) {
+		return false
+	}
+
+	latitude := math.Float64frombits(binary.LittleEndian.Uint64(header[72:]))
+	longitude := math.Float64frombits(binary.LittleEndian.Uint64(header[80:]))
+	detection.check(isPlausibleCoordinate(latitude, longitude), 1)
+	detection.check(isWholeRecordsSize(size, channelsCount), 1)
+	return true
+}
+
+
+func scoreBaikal7(header []byte, size int64) float64 {
+	detection := detectionScore{}
+	if !scoreBaikalCommon(header, size, &detection) {
+		return 0
+	}
+
+	frequency := binary.LittleEndian.Uint16(header[22:])
+	if !isPlausibleFrequency(float64(frequency)) {
+		return 0
+	}
+
+	timeBegin := binary.LittleEndian.Uint64(header[104:])
+	isGoodTime := timeBegin > 0 && isPlausibleDatetime(getDatetimeStartBaikal7(timeBegin))
+	detection.check(isGoodTime, 2)
+	return detection.score / detection.total
+}
+
+
+func scoreBaikal8(header []byte, size int64) float64 {
+	detection := detectionScore{}
+	if !scoreBaikalCommon(header, size, &detection) {
+		return 0
+	}
+
+	day := int(binary.LittleEndian.Uint16(header[6:]))
+	month := int(binary.LittleEndian.Uint16(header[8:]))
+	year := int(binary.LittleEndian.Uint16(header[10:]))
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	isGoodDate := date.Day() == day && int(date.Month()) == month && isPlausibleDatetime(date)
+	detection.check(isGoodDate, 1)
+
+	sampleInterval := math.Float64frombits(binary.LittleEndian.Uint64(header[48:]))
+	if !(sampleInterval > 0 && sampleInterval <= 1) {
+		return 0
+	}
+	frequency := 1 / sampleInterval
+	if !isPlausibleFrequency(frequency) {
+		return 0
+	}
+	detection.check(math.Abs(frequency - math.Round(frequency)) < 1e-6, 2)
+
+	secondsOfDay := math.Float64frombits(binary.LittleEndian.Uint64(header[56:]))
+	detection.check(secondsOfDay >= 0 && secondsOfDay < 86400, 1)
+	return detection.score / detection.total
+}
+
+
+func scoreSigma(header []byte, size int64) float64 {
+	detection := detectionScore{}
+	channelsCount := binary.LittleEndian.Uint16(header[12:])
+	if !isPlausibleChannelsCount(channelsCount) {
+		return 0
+	}
+	detection.check(isWholeRecordsSize(size, channelsCount), 1)
+
+	frequency := binary.LittleEndian.Uint16(header[24:])
+	if !isPlausibleFrequency(float64(frequency)) {
+		return 0
+	}
+
+	_, err := getCoordinatesSigma(string(header[48:57]), string(header[40:48]))
+	detection.check(err == nil, 1)
+
+	dateNum := binary.LittleEndian.Uint32(header[60:])
+	timeNum := binary.LittleEndian.Uint32(header[64:])
+	_, err = getDatetimeStartSigma(dateNum, timeNum)
+	detection.check(err == nil, 2)
+	return detection.score / detection.total
+}
+
+
+func DetectFormats(reader io.ReaderAt, size int64, extensionHint string) ([]FormatDetection, error) {
+	if size < MAIN_HEADER_SIZE {
+		return nil, BadHeaderData{message: "File is shorter than main header"}
+	}
+
+	header := make([]byte, MAIN_HEADER_SIZE)
+	if _, err := reader.ReadAt(header, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	hintFormat := formatTypeByExtension(extensionHint)
+	scores := map[string]float64{
+		BAIKAL7_FMT: scoreBaikal7(header, size),
+		BAIKAL8_FMT: scoreBaikal8(header, size),
+		SIGMA_FMT: scoreSigma(header, size),
+	}
+
+	detections := []FormatDetection{}
+	for formatType, score := range scores {
+		if score == 0 {
+			continue
+		}
+		detections = append(detections, FormatDetection{FormatType: formatType, Confidence: score})
+	}
+
+	sort.Slice(detections, func(i, j int) bool {
+		if detections[i].Confidence == detections[j].Confidence {
+			isHintFirst := detections[i].FormatType == hintFormat
+			isHintSecond := detections[j].FormatType == hintFormat
+			if isHintFirst != isHintSecond {
+				return isHintFirst
+			}
+			return detections[i].FormatType < detections[j].FormatType
+		}
+		return detections[i].Confidence > detections[j].Confidence
+	})
+	return detections, nil
+}
+
+
+func DetectFormat(reader io.ReaderAt, size int64, extensionHint string) (FormatDetection, error) {
+	detections, err := DetectFormats(reader, size, extensionHint)
+	if err != nil {
+		return FormatDetection{}, err
+	}
+
+	if len(detections) == 0 || detections[0].Confidence < MIN_DETECTION_CONFIDENCE {
+		return FormatDetection{}, BadFilePath{message: "Invalid file format"}
+	}
+	return detections[0], nil
+}
+
+
+func DetectFileFormat(path string) (FormatDetection, error) {
+	if len(path) == 0 {
+		return FormatDetection{}, BadFilePath{message: "Empty file path"}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return FormatDetection{}, fileError(err, path)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return FormatDetection{}, fileError(err, path)
+	}
+
+	detection, err := DetectFormat(file, info.Size(), fileExtension(path))
+	return detection, withPath(err, path)
+}
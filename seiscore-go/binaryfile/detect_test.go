@@ -0,0 +1,131 @@
+package binaryfile
+
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+
+func testHeader() FileHeader {
+	return FileHeader{
+		Frequency: 1000,
+		DatetimeStart: time.Date(2022, 1, 20, 8, 21, 5, 0, time.UTC),
+		Coordinate: Coordinate{Latitude: 55.5, Longitude: 84.25},
+		StationName: "TEST"}
+}
+
+
+func testSignals(channelsCount int, samplesCount int) [][]int32 {
+	signals := make([][]int32, channelsCount)
+	for i := range signals {
+		signals[i] = make([]int32, samplesCount)
+		for j := range signals[i] {
+			signals[i][j] = int32((j * (i + 3)) % 2001 - 1000)
+		}
+	}
+	return signals
+}
+
+
+func TestDetectRenamedFiles(t *testing.T) {
+	formatTypes := []string{BAIKAL7_FMT, BAIKAL8_FMT, SIGMA_FMT}
+	for _, formatType := range formatTypes {
+		for _, extensionFormat := range formatTypes {
+			path := filepath.Join(t.TempDir(), "record." + BINARY_FILE_FORMATS[extensionFormat])
+			err := WriteBinaryFile(path, formatType, testHeader(), testSignals(3, 1000))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			detection, err := DetectFileFormat(path)
+			if err != nil {
+				t.Fatalf("%s file with .%s extension: %v", formatType, BINARY_FILE_FORMATS[extensionFormat], err)
+			}
+			if detection.FormatType != formatType {
+				t.Errorf(
+					"%s file with .%s extension detected as %s",
+					formatType, BINARY_FILE_FORMATS[extensionFormat], detection.FormatType)
+			}
+		}
+	}
+}
+
+
+func TestDetectUnknownExtension(t *testing.T) {
+	for _, formatType := range []string{BAIKAL7_FMT, BAIKAL8_FMT, SIGMA_FMT} {
+		path := filepath.Join(t.TempDir(), "record.bin")
+		if err := WriteBinaryFile(path, formatType, testHeader(), testSignals(3, 1000)); err != nil {
+			t.Fatal(err)
+		}
+
+		detection, err := DetectFileFormat(path)
+		if err != nil {
+			t.Fatalf("%s file: %v", formatType, err)
+		}
+		if detection.FormatType != formatType {
+			t.Errorf("%s file detected as %s", formatType, detection.FormatType)
+		}
+	}
+}
+
+
+func TestMissingFileErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.00")
+	_, detectErr := DetectFileFormat(path)
+	_, openErr := OpenRecording(path)
+	_, headerErr := ReadBaikal7Header(path)
+	_, validateErr := ValidateFile(path)
+	_, archiveErr := OpenArchive(path)
+	for _, err := range []error{detectErr, openErr, headerErr, validateErr, archiveErr} {
+		var readError ReadError
+		if !errors.As(err, &readError) || readError.Path != path {
+			t.Errorf("Error %v has no file path", err)
+		}
+		if !errors.Is(err, BadFilePath{}) || !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Error %v is not BadFilePath wrapping fs.ErrNotExist", err)
+		}
+	}
+}
+
+
+func TestDetectZeroFrequency(t *testing.T) {
+	frequencyFields := map[string]struct{ offset, size int64 }{
+		BAIKAL7_FMT: {22, 2},
+		BAIKAL8_FMT: {48, 8},
+		SIGMA_FMT: {24, 2},
+	}
+	for formatType, field := range frequencyFields {
+		path := filepath.Join(t.TempDir(), "record." + BINARY_FILE_FORMATS[formatType])
+		if err := WriteBinaryFile(path, formatType, testHeader(), testSignals(3, 1000)); err != nil {
+			t.Fatal(err)
+		}
+
+		file, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, err := file.WriteAt(make([]byte, field.size), field.offset); err != nil {
+			t.Fatal(err)
+		}
+		info, err := file.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		detections, err := DetectFormats(file, info.Size(), BINARY_FILE_FORMATS[formatType])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, detection := range detections {
+			if detection.FormatType == formatType {
+				t.Errorf("%s file with zero frequency scored %g", formatType, detection.Confidence)
+			}
+		}
+	}
+}
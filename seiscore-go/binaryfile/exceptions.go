@@ -1,7 +1,9 @@
 package binaryfile
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 )
 
 
@@ -13,14 +15,29 @@ func (customError BadHeaderData) Error() string {
 	return fmt.Sprintf("BadHeaderData: %s", customError.message)
 }
 
+func (customError BadHeaderData) Is(target error) bool {
+	_, isSameType := target.(BadHeaderData)
+	return isSameType
+}
+
 
 type BadFilePath struct {
 	message string
+	err error
 }
 
 func (customError BadFilePath) Error() string {
 	return fmt.Sprintf("BadFilePath: %s", customError.message)
-} 
+}
+
+func (customError BadFilePath) Is(target error) bool {
+	_, isSameType := target.(BadFilePath)
+	return isSameType
+}
+
+func (customError BadFilePath) Unwrap() error {
+	return customError.err
+}
 
 
 type InvalidResampleFrequency struct {
@@ -31,6 +48,11 @@ func (customError InvalidResampleFrequency) Error() string {
 	return fmt.Sprintf("InvalidResampleFrequency: %s", customError.message)
 }
 
+func (customError InvalidResampleFrequency) Is(target error) bool {
+	_, isSameType := target.(InvalidResampleFrequency)
+	return isSameType
+}
+
 
 type InvalidDatetimeValue struct {
 	message string
@@ -40,6 +62,11 @@ func (customError InvalidDatetimeValue) Error() string {
 	return fmt.Sprintf("InvalidDatetimeValue: %s", customError.message)
 }
 
+func (customError InvalidDatetimeValue) Is(target error) bool {
+	_, isSameType := target.(InvalidDatetimeValue)
+	return isSameType
+}
+
 
 type UnknownComponentName struct {
 	message string
@@ -49,6 +76,11 @@ func (customError UnknownComponentName) Error() string {
 	return fmt.Sprintf("UnknownComponentName: %s", customError.message)
 }
 
+func (customError UnknownComponentName) Is(target error) bool {
+	_, isSameType := target.(UnknownComponentName)
+	return isSameType
+}
+
 
 type BadSignalData struct {
 	message string
@@ -56,4 +88,65 @@ type BadSignalData struct {
 
 func (customError BadSignalData) Error() string {
 	return fmt.Sprintf("BadSignalData: %s", customError.message)
-}
\ No newline at end of file
+}
+
+func (customError BadSignalData) Is(target error) bool {
+	_, isSameType := target.(BadSignalData)
+	return isSameType
+}
+
+
+type ReadError struct {
+	Path string
+	Offset int64
+	Field string
+	Err error
+}
+
+func (customError ReadError) Error() string {
+	location := customError.Path
+	if len(customError.Field) != 0 {
+		if len(location) != 0 {
+			location += ": "
+		}
+		location += fmt.Sprintf("%s at offset %d", customError.Field, customError.Offset)
+	}
+
+	if len(location) == 0 {
+		return customError.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", location, customError.Err)
+}
+
+func (customError ReadError) Unwrap() error {
+	return customError.Err
+}
+
+
+func withPath(err error, path string) error {
+	if err == nil {
+		return nil
+	}
+
+	if readError, isReadError := err.(ReadError); isReadError {
+		if len(readError.Path) == 0 {
+			readError.Path = path
+		}
+		return readError
+	}
+	return ReadError{Path: path, Err: err}
+}
+
+
+func fileError(err error, path string) error {
+	if err == nil {
+		return nil
+	}
+
+	message := err.Error()
+	var pathError *fs.PathError
+	if errors.As(err, &pathError) {
+		message = fmt.Sprintf("%s: %v", pathError.Op, pathError.Err)
+	}
+	return ReadError{Path: path, Err: BadFilePath{message: message, err: err}}
+}
@@ -0,0 +1,221 @@
+package binaryfile
+
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+
+// Main header layout (120 bytes) for Baikal7 and Baikal8:
+//   0 channels count, 2 test type, 4 version, 6 day, 8 month, 10 year,
+//   12 satellites count, 14 GPS valid flag, 16 GPS sync flag, 18 ADC bits,
+//   20 ADC gain, 22 frequency, 24 ADC filter, 32 station name [16],
+//   48 sample interval, 56 seconds of day, 64 time correction, 72 latitude,
+//   80 longitude, 88 altitude, 96 instrument serial, 104 time begin.
+//
+// Main header layout (120 bytes) for Sigma:
+//   0 station name [12], 12 channels count, 14 version, 16 ADC bits,
+//   18 ADC gain, 20 ADC filter, 22 test type, 24 frequency,
+//   26 satellites count, 28 GPS valid flag, 30 GPS sync flag,
+//   32 instrument serial, 40 latitude [8], 48 longitude [9], 60 date,
+//   64 time, 68 time correction.
+//
+// Channel header layout (72 bytes) is the same for all formats:
+//   0 physical number, 2 gain, 4 name [24], 28 units [16], 48 sensitivity.
+
+const (
+	STATION_NAME_SIZE_BAIKAL, STATION_NAME_SIZE_SIGMA = 16, 12
+	CHANNEL_NAME_SIZE, CHANNEL_UNITS_SIZE = 24, 16
+)
+
+
+type AdcSettings struct {
+	Bits uint16
+	Gain uint16
+	Filter uint16
+}
+
+
+type GpsInfo struct {
+	SatellitesCount uint16
+	ValidFlag uint16
+	SyncFlag uint16
+	Altitude float64
+}
+
+func (gps GpsInfo) IsValid() bool {
+	return gps.ValidFlag != 0
+}
+
+func (gps GpsInfo) IsSynchronized() bool {
+	return gps.SyncFlag != 0
+}
+
+
+type TimeSyncInfo struct {
+	SampleInterval float64
+	SecondsOfDay float64
+	TimeBegin uint64
+	Correction float64
+}
+
+
+type ChannelHeader struct {
+	PhysicalNumber uint16
+	Gain uint16
+	Name string
+	Units string
+	Sensitivity float64
+}
+
+
+func (header FileHeader) ChannelNames() []string {
+	names := make([]string, header.ChannelsCount)
+	for i := range names {
+		if i < len(header.Channels) && len(header.Channels[i].Name) != 0 {
+			names[i] = header.Channels[i].Name
+		} else if len(names) == len(COMPONENTS_ORDER) {
+			names[i] = string(COMPONENTS_ORDER[i])
+		} else {
+			names[i] = strconv.Itoa(i + 1)
+		}
+	}
+	return names
+}
+
+func (header FileHeader) ChannelIndex(name string) (int, error) {
+	for i, channelName := range header.ChannelNames() {
+		if channelName == name {
+			return i, nil
+		}
+	}
+	return 0, UnknownComponentName{message: name}
+}
+
+
+func trimChars(value string) string {
+	return strings.TrimRight(value, "\x00 ")
+}
+
+
+func readChannelHeaders(fields *fieldReader, channelsCount uint16) []ChannelHeader {
+	channels := make([]ChannelHeader, channelsCount)
+	for i := range channels {
+		offset := uint16(MAIN_HEADER_SIZE + CHANNEL_HEADER_SIZE * i)
+		field := fmt.Sprintf("channel %d ", i + 1)
+		channels[i] = ChannelHeader{
+			PhysicalNumber: fields.unsignedShort(field + "physical number", offset),
+			Gain: fields.unsignedShort(field + "gain", offset + 2),
+			Name: trimChars(fields.chars(field + "name", offset + 4, CHANNEL_NAME_SIZE)),
+			Units: trimChars(fields.chars(field + "units", offset + 28, CHANNEL_UNITS_SIZE)),
+			Sensitivity: fields.double(field + "sensitivity", offset + 48)}
+	}
+	return channels
+}
+
+
+func readBaikalHeaderFields(reader io.ReaderAt, header *FileHeader) error {
+	fields := &fieldReader{reader: reader}
+	header.TestType = fields.unsignedShort("test type", 2)
+	header.Version = fields.unsignedShort("version", 4)
+	header.StationName = trimChars(fields.chars("station name", 32, STATION_NAME_SIZE_BAIKAL))
+	header.InstrumentSerial = fields.unsignedInt("instrument serial", 96)
+
+	header.Adc = AdcSettings{
+		Bits: fields.unsignedShort("ADC bits", 18),
+		Gain: fields.unsignedShort("ADC gain", 20),
+		Filter: fields.unsignedShort("ADC filter", 24)}
+
+	gpsFlags := fields.unsignedShorts("GPS flags", 12, 3)
+	header.Gps = GpsInfo{
+		SatellitesCount: gpsFlags[0],
+		ValidFlag: gpsFlags[1],
+		SyncFlag: gpsFlags[2],
+		Altitude: fields.double("altitude", 88)}
+
+	timeValues := fields.doubles("time values", 48, 3)
+	header.TimeSync = TimeSyncInfo{
+		SampleInterval: timeValues[0],
+		SecondsOfDay: timeValues[1],
+		TimeBegin: fields.long("time begin", 104),
+		Correction: timeValues[2]}
+
+	header.Channels = readChannelHeaders(fields, header.ChannelsCount)
+	return fields.err
+}
+
+
+func readSigmaHeaderFields(reader io.ReaderAt, header *FileHeader) error {
+	fields := &fieldReader{reader: reader}
+	header.StationName = trimChars(fields.chars("station name", 0, STATION_NAME_SIZE_SIGMA))
+	header.Version = fields.unsignedShort("version", 14)
+	header.TestType = fields.unsignedShort("test type", 22)
+	header.InstrumentSerial = fields.unsignedInt("instrument serial", 32)
+
+	adcValues := fields.unsignedShorts("ADC settings", 16, 3)
+	header.Adc = AdcSettings{Bits: adcValues[0], Gain: adcValues[1], Filter: adcValues[2]}
+
+	gpsFlags := fields.unsignedShorts("GPS flags", 26, 3)
+	header.Gps = GpsInfo{
+		SatellitesCount: gpsFlags[0],
+		ValidFlag: gpsFlags[1],
+		SyncFlag: gpsFlags[2]}
+
+	header.TimeSync = TimeSyncInfo{
+		SampleInterval: 1 / float64(header.Frequency),
+		Correction: fields.double("time correction", 68)}
+
+	header.Channels = readChannelHeaders(fields, header.ChannelsCount)
+	return fields.err
+}
+
+
+func encodeChannelHeaders(buffer []byte, channels []ChannelHeader) {
+	for i, channel := range channels {
+		offset := MAIN_HEADER_SIZE + CHANNEL_HEADER_SIZE * i
+		putUnsignedShort(buffer, offset, channel.PhysicalNumber)
+		putUnsignedShort(buffer, offset + 2, channel.Gain)
+		putChars(buffer[:offset + 4 + CHANNEL_NAME_SIZE], offset + 4, channel.Name)
+		putChars(buffer[:offset + 28 + CHANNEL_UNITS_SIZE], offset + 28, channel.Units)
+		putDouble(buffer, offset + 48, channel.Sensitivity)
+	}
+}
+
+
+func encodeBaikalHeaderFields(buffer []byte, header FileHeader) {
+	putUnsignedShort(buffer, 2, header.TestType)
+	putUnsignedShort(buffer, 4, header.Version)
+	putUnsignedShort(buffer, 12, header.Gps.SatellitesCount)
+	putUnsignedShort(buffer, 14, header.Gps.ValidFlag)
+	putUnsignedShort(buffer, 16, header.Gps.SyncFlag)
+	putUnsignedShort(buffer, 18, header.Adc.Bits)
+	putUnsignedShort(buffer, 20, header.Adc.Gain)
+	putUnsignedShort(buffer, 22, header.Frequency)
+	putUnsignedShort(buffer, 24, header.Adc.Filter)
+	putChars(buffer[:32 + STATION_NAME_SIZE_BAIKAL], 32, header.StationName)
+	putDouble(buffer, 64, header.TimeSync.Correction)
+	putDouble(buffer, 72, header.Coordinate.Latitude)
+	putDouble(buffer, 80, header.Coordinate.Longitude)
+	putDouble(buffer, 88, header.Gps.Altitude)
+	putUnsignedInt(buffer, 96, header.InstrumentSerial)
+	encodeChannelHeaders(buffer, header.Channels)
+}
+
+
+func encodeSigmaHeaderFields(buffer []byte, header FileHeader) {
+	putChars(buffer[:STATION_NAME_SIZE_SIGMA], 0, header.StationName)
+	putUnsignedShort(buffer, 14, header.Version)
+	putUnsignedShort(buffer, 16, header.Adc.Bits)
+	putUnsignedShort(buffer, 18, header.Adc.Gain)
+	putUnsignedShort(buffer, 20, header.Adc.Filter)
+	putUnsignedShort(buffer, 22, header.TestType)
+	putUnsignedShort(buffer, 26, header.Gps.SatellitesCount)
+	putUnsignedShort(buffer, 28, header.Gps.ValidFlag)
+	putUnsignedShort(buffer, 30, header.Gps.SyncFlag)
+	putUnsignedInt(buffer, 32, header.InstrumentSerial)
+	putDouble(buffer, 68, header.TimeSync.Correction)
+	encodeChannelHeaders(buffer, header.Channels)
+}
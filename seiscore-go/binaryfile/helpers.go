@@ -4,19 +4,25 @@ package binaryfile
 import (
 	"bytes"
 	"encoding/binary"
-	"os"
+	"io"
 )
 
 
-func readBinary(file *os.File, bytesCount uint16, skippingBytes uint16) []byte {
+func readBinary(reader io.ReaderAt, bytesCount uint16, skippingBytes uint16) ([]byte, error) {
 	bytes := make([]byte, bytesCount)
-	file.ReadAt(bytes, int64(skippingBytes))
-	return bytes
+	readCount, err := reader.ReadAt(bytes, int64(skippingBytes))
+	if readCount == len(bytes) {
+		return bytes, nil
+	}
+	if err == nil || err == io.EOF {
+		err = BadHeaderData{message: "Unexpected end of file"}
+	}
+	return nil, err
 }
 
 
 type CharType struct {
-	filePointer *os.File
+	reader io.ReaderAt
 	skippingBytes uint16
 	elementsCount uint16
 }
@@ -25,16 +31,16 @@ func (dataType CharType) ByteSize() uint8 {
 	return 1
 }
 
-func (dataType CharType) convert() string {
-	bytesVal := readBinary(
-		dataType.filePointer, uint16(dataType.ByteSize()) * dataType.elementsCount, 
+func (dataType CharType) convert() (string, error) {
+	bytesVal, err := readBinary(
+		dataType.reader, uint16(dataType.ByteSize()) * dataType.elementsCount, 
 		dataType.skippingBytes)
-	return string(bytesVal)
+	return string(bytesVal), err
 }
 
 
 type UnsignedShortType struct {
-	filePointer *os.File
+	reader io.ReaderAt
 	skippingBytes uint16
 	elementsCount uint16	
 }
@@ -43,29 +49,35 @@ func (dataType UnsignedShortType) ByteSize() uint8 {
 	return 2
 }
 
-func (dataType UnsignedShortType) getBytes() []byte {
+func (dataType UnsignedShortType) getBytes() ([]byte, error) {
 	return readBinary(
-		dataType.filePointer, uint16(dataType.ByteSize()) * dataType.elementsCount, 
+		dataType.reader, uint16(dataType.ByteSize()) * dataType.elementsCount, 
 		dataType.skippingBytes)
 }
 
-func (dataType UnsignedShortType) convertToNumber() uint16 {
-	buffer := bytes.NewBuffer(dataType.getBytes())
+func (dataType UnsignedShortType) convertToNumber() (uint16, error) {
 	var result uint16
-	binary.Read(buffer, binary.LittleEndian, &result)
-	return result
-}
-
-func (dataType UnsignedShortType) convertToArray() []uint16 {
-	buffer := bytes.NewBuffer(dataType.getBytes())
+	bytesVal, err := dataType.getBytes()
+	if err != nil {
+		return result, err
+	}
+	err = binary.Read(bytes.NewBuffer(bytesVal), binary.LittleEndian, &result)
+	return result, err
+}
+
+func (dataType UnsignedShortType) convertToArray() ([]uint16, error) {
+	bytesVal, err := dataType.getBytes()
+	if err != nil {
+		return nil, err
+	}
 	result := make([]uint16, dataType.elementsCount)
-	binary.Read(buffer, binary.LittleEndian, &result)
-	return result
+	err = binary.Read(bytes.NewBuffer(bytesVal), binary.LittleEndian, &result)
+	return result, err
 }
 
 
 type UnsignedIntType struct {
-	filePointer *os.File
+	reader io.ReaderAt
 	skippingBytes uint16
 	elementsCount uint16
 }
@@ -74,29 +86,35 @@ func (dataType UnsignedIntType) ByteSize() uint8 {
 	return 4
 }
 
-func (dataType UnsignedIntType) getBytes() []byte {
+func (dataType UnsignedIntType) getBytes() ([]byte, error) {
 	return readBinary(
-		dataType.filePointer, uint16(dataType.ByteSize()) * dataType.elementsCount, 
+		dataType.reader, uint16(dataType.ByteSize()) * dataType.elementsCount, 
 		dataType.skippingBytes)
 }
 
-func (dataType UnsignedIntType) convertToNumber() uint32 {
-	buffer := bytes.NewBuffer(dataType.getBytes())
+func (dataType UnsignedIntType) convertToNumber() (uint32, error) {
 	var result uint32
-	binary.Read(buffer, binary.LittleEndian, &result)
-	return result
-}
-
-func (dataType UnsignedIntType) convertToArray() []uint32 {
-	buffer := bytes.NewBuffer(dataType.getBytes())
+	bytesVal, err := dataType.getBytes()
+	if err != nil {
+		return result, err
+	}
+	err = binary.Read(bytes.NewBuffer(bytesVal), binary.LittleEndian, &result)
+	return result, err
+}
+
+func (dataType UnsignedIntType) convertToArray() ([]uint32, error) {
+	bytesVal, err := dataType.getBytes()
+	if err != nil {
+		return nil, err
+	}
 	result := make([]uint32, dataType.elementsCount)
-	binary.Read(buffer, binary.LittleEndian, &result)
-	return result
+	err = binary.Read(bytes.NewBuffer(bytesVal), binary.LittleEndian, &result)
+	return result, err
 }
 
 
 type DoubleType struct {
-	filePointer *os.File
+	reader io.ReaderAt
 	skippingBytes uint16
 	elementsCount uint16
 }
@@ -105,29 +123,35 @@ func (dataType DoubleType) ByteSize() uint8 {
 	return 8
 }
 
-func (dataType DoubleType) getBytes() []byte {
+func (dataType DoubleType) getBytes() ([]byte, error) {
 	return readBinary(
-		dataType.filePointer, uint16(dataType.ByteSize()) * dataType.elementsCount, 
+		dataType.reader, uint16(dataType.ByteSize()) * dataType.elementsCount, 
 		dataType.skippingBytes)
 }
 
-func (dataType DoubleType) convertToNumber() float64 {
-	buffer := bytes.NewBuffer(dataType.getBytes())
+func (dataType DoubleType) convertToNumber() (float64, error) {
 	var result float64
-	binary.Read(buffer, binary.LittleEndian, &result)
-	return result
-}
-
-func (dataType DoubleType) convertToArray() []float64 {
-	buffer := bytes.NewBuffer(dataType.getBytes())
+	bytesVal, err := dataType.getBytes()
+	if err != nil {
+		return result, err
+	}
+	err = binary.Read(bytes.NewBuffer(bytesVal), binary.LittleEndian, &result)
+	return result, err
+}
+
+func (dataType DoubleType) convertToArray() ([]float64, error) {
+	bytesVal, err := dataType.getBytes()
+	if err != nil {
+		return nil, err
+	}
 	result := make([]float64, dataType.elementsCount)
-	binary.Read(buffer, binary.LittleEndian, &result)
-	return result
+	err = binary.Read(bytes.NewBuffer(bytesVal), binary.LittleEndian, &result)
+	return result, err
 }
 
 
 type LongType struct {
-	filePointer *os.File
+	reader io.ReaderAt
 	skippingBytes uint16
 	elementsCount uint16
 }
@@ -136,22 +160,91 @@ func (dataType LongType) ByteSize() uint8 {
 	return 8
 }
 
-func (dataType LongType) getBytes() []byte {
+func (dataType LongType) getBytes() ([]byte, error) {
 	return readBinary(
-		dataType.filePointer, uint16(dataType.ByteSize()) * dataType.elementsCount, 
+		dataType.reader, uint16(dataType.ByteSize()) * dataType.elementsCount, 
 		dataType.skippingBytes)
 }
 
-func (dataType LongType) convertToNumber() uint64 {
-	buffer := bytes.NewBuffer(dataType.getBytes())
+func (dataType LongType) convertToNumber() (uint64, error) {
 	var result uint64
-	binary.Read(buffer, binary.LittleEndian, &result)
-	return result
+	bytesVal, err := dataType.getBytes()
+	if err != nil {
+		return result, err
+	}
+	err = binary.Read(bytes.NewBuffer(bytesVal), binary.LittleEndian, &result)
+	return result, err
+}
+
+func (dataType LongType) convertToArray() ([]uint64, error) {
+	bytesVal, err := dataType.getBytes()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]uint64, dataType.elementsCount)
+	err = binary.Read(bytes.NewBuffer(bytesVal), binary.LittleEndian, &result)
+	return result, err
 }
 
-func (dataType LongType) convertToArray() []uint64 {
-	buffer := bytes.NewBuffer(dataType.getBytes())
-	result := make([]uint64, dataType.elementsCount)
-	binary.Read(buffer, binary.LittleEndian, &result)
-	return result
+
+type fieldReader struct {
+	reader io.ReaderAt
+	err error
+}
+
+func (fields *fieldReader) fail(field string, offset uint16, err error) {
+	if fields.err == nil && err != nil {
+		fields.err = ReadError{Offset: int64(offset), Field: field, Err: err}
+	}
+}
+
+func (fields *fieldReader) chars(field string, offset uint16, count uint16) string {
+	value, err := CharType{fields.reader, offset, count}.convert()
+	fields.fail(field, offset, err)
+	return value
+}
+
+func (fields *fieldReader) unsignedShorts(field string, offset uint16, count uint16) []uint16 {
+	values, err := UnsignedShortType{fields.reader, offset, count}.convertToArray()
+	if err != nil {
+		fields.fail(field, offset, err)
+		return make([]uint16, count)
+	}
+	return values
+}
+
+func (fields *fieldReader) unsignedShort(field string, offset uint16) uint16 {
+	return fields.unsignedShorts(field, offset, 1)[0]
+}
+
+func (fields *fieldReader) unsignedInts(field string, offset uint16, count uint16) []uint32 {
+	values, err := UnsignedIntType{fields.reader, offset, count}.convertToArray()
+	if err != nil {
+		fields.fail(field, offset, err)
+		return make([]uint32, count)
+	}
+	return values
+}
+
+func (fields *fieldReader) unsignedInt(field string, offset uint16) uint32 {
+	return fields.unsignedInts(field, offset, 1)[0]
+}
+
+func (fields *fieldReader) doubles(field string, offset uint16, count uint16) []float64 {
+	values, err := DoubleType{fields.reader, offset, count}.convertToArray()
+	if err != nil {
+		fields.fail(field, offset, err)
+		return make([]float64, count)
+	}
+	return values
+}
+
+func (fields *fieldReader) double(field string, offset uint16) float64 {
+	return fields.doubles(field, offset, 1)[0]
+}
+
+func (fields *fieldReader) long(field string, offset uint16) uint64 {
+	value, err := LongType{fields.reader, offset, 1}.convertToNumber()
+	fields.fail(field, offset, err)
+	return value
 }
@@ -0,0 +1,236 @@
+//go:build linux
+// +build linux
+
+package binaryfile
+
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+
+type mappingState struct {
+	lock sync.RWMutex
+	isClosed bool
+}
+
+
+type MappedRecords struct {
+	records []byte
+	channelsCount int
+	state *mappingState
+	Frequency uint16
+	DatetimeStart time.Time
+}
+
+func (view MappedRecords) ChannelsCount() int {
+	return view.channelsCount
+}
+
+func (view MappedRecords) SamplesCount() int {
+	return len(view.records) / (4 * view.channelsCount)
+}
+
+func (view MappedRecords) DatetimeStop() time.Time {
+	duration := float64(view.SamplesCount()) / float64(view.Frequency) * 1e9
+	return view.DatetimeStart.Add(time.Duration(duration))
+}
+
+func (view MappedRecords) lockMapping() error {
+	if view.state == nil {
+		return os.ErrClosed
+	}
+
+	view.state.lock.RLock()
+	if view.state.isClosed {
+		view.state.lock.RUnlock()
+		return os.ErrClosed
+	}
+	return nil
+}
+
+func (view MappedRecords) unlockMapping() {
+	view.state.lock.RUnlock()
+}
+
+func (view MappedRecords) checkChannelIndex(channelIndex int) error {
+	if channelIndex < 0 || channelIndex >= view.channelsCount {
+		return UnknownComponentName{message: fmt.Sprint(channelIndex)}
+	}
+	return nil
+}
+
+func (view MappedRecords) checkSampleIndex(index int) error {
+	if index < 0 || index >= view.SamplesCount() {
+		return BadSignalData{message: fmt.Sprintf("Sample index %d is out of range", index)}
+	}
+	return nil
+}
+
+func (view MappedRecords) sample(index int, channelIndex int) int32 {
+	offset := (index * view.channelsCount + channelIndex) * 4
+	return int32(binary.LittleEndian.Uint32(view.records[offset:offset + 4]))
+}
+
+func (view MappedRecords) Sample(index int, channelIndex int) (int32, error) {
+	if err := view.lockMapping(); err != nil {
+		return 0, err
+	}
+	defer view.unlockMapping()
+	if err := view.checkChannelIndex(channelIndex); err != nil {
+		return 0, err
+	}
+	if err := view.checkSampleIndex(index); err != nil {
+		return 0, err
+	}
+	return view.sample(index, channelIndex), nil
+}
+
+func (view MappedRecords) Record(index int) ([]byte, error) {
+	if err := view.lockMapping(); err != nil {
+		return nil, err
+	}
+	defer view.unlockMapping()
+	if err := view.checkSampleIndex(index); err != nil {
+		return nil, err
+	}
+
+	recordSize := 4 * view.channelsCount
+	record := make([]byte, recordSize)
+	copy(record, view.records[index * recordSize:(index + 1) * recordSize])
+	return record, nil
+}
+
+func (view MappedRecords) sampleIndex(datetime time.Time) int {
+	secondsDiff := datetime.Sub(view.DatetimeStart).Seconds()
+	return int(math.Round(secondsDiff * float64(view.Frequency)))
+}
+
+func (view MappedRecords) Slice(timeStart time.Time, timeStop time.Time) (MappedRecords, error) {
+	if err := view.lockMapping(); err != nil {
+		return MappedRecords{}, err
+	}
+	defer view.unlockMapping()
+
+	startIndex := view.sampleIndex(timeStart)
+	stopIndex := view.sampleIndex(timeStop)
+	if startIndex < 0 {
+		return MappedRecords{}, InvalidDatetimeValue{message: "Reading time start is less than recording time start"}
+	}
+	if stopIndex > view.SamplesCount() {
+		return MappedRecords{}, InvalidDatetimeValue{message: "Reading time stop is more than recording time stop"}
+	}
+	if startIndex >= stopIndex {
+		return MappedRecords{}, InvalidDatetimeValue{message: "Reading time stop is less than reading time start"}
+	}
+
+	recordSize := 4 * view.channelsCount
+	offset := time.Duration(float64(startIndex) / float64(view.Frequency) * 1e9)
+	return MappedRecords{
+		records: view.records[startIndex * recordSize:stopIndex * recordSize],
+		channelsCount: view.channelsCount,
+		state: view.state,
+		Frequency: view.Frequency,
+		DatetimeStart: view.DatetimeStart.Add(offset)}, nil
+}
+
+// WithRecords gives zero-copy access to the mapped interleaved little-endian
+// records of the view. The slice is read-only and valid only until fn returns,
+// Close waits for it, so fn must not keep or modify it.
+func (view MappedRecords) WithRecords(fn func(records []byte) error) error {
+	if err := view.lockMapping(); err != nil {
+		return err
+	}
+	defer view.unlockMapping()
+	return fn(view.records)
+}
+
+func (view MappedRecords) Channel(channelIndex int) ([]int32, error) {
+	if err := view.lockMapping(); err != nil {
+		return []int32{}, err
+	}
+	defer view.unlockMapping()
+	if err := view.checkChannelIndex(channelIndex); err != nil {
+		return []int32{}, err
+	}
+
+	signal := make([]int32, view.SamplesCount())
+	for i := range signal {
+		signal[i] = view.sample(i, channelIndex)
+	}
+	return signal, nil
+}
+
+
+// MappedFile keeps the file data mapped until Close. Views made by Slice
+// share the mapping. Sample, Record and Channel copy data out and return
+// os.ErrClosed after Close, so no returned value refers to the unmapped
+// memory; WithRecords is the zero-copy access for the duration of a callback.
+type MappedFile struct {
+	MappedRecords
+	Header FileHeader
+	mapping []byte
+}
+
+
+func (recording *Recording) Map() (*MappedFile, error) {
+	header := recording.header
+	channelsCount := int(header.ChannelsCount)
+	headerSize := headerMemorySize(channelsCount)
+	file, isFile := recording.reader.(*os.File)
+	if !isFile {
+		return nil, withPath(BadFilePath{message: "Recording is not backed by a file"}, recording.path)
+	}
+
+	size := recording.size
+	if size < int64(headerSize) {
+		return nil, withPath(BadHeaderData{message: "File is shorter than header"}, recording.path)
+	}
+
+	mapping, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, withPath(err, recording.path)
+	}
+
+	recordSize := 4 * channelsCount
+	recordsSize := (len(mapping) - headerSize) / recordSize * recordSize
+	return &MappedFile{
+		MappedRecords: MappedRecords{
+			records: mapping[headerSize:headerSize + recordsSize],
+			channelsCount: channelsCount,
+			state: &mappingState{},
+			Frequency: header.Frequency,
+			DatetimeStart: recording.DatetimeStart()},
+		Header: header,
+		mapping: mapping}, nil
+}
+
+
+func (binFile BinaryFile) Map() (*MappedFile, error) {
+	recording, err := binFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer recording.Close()
+	return recording.Map()
+}
+
+func (mapped *MappedFile) Close() error {
+	mapped.state.lock.Lock()
+	defer mapped.state.lock.Unlock()
+	if mapped.mapping == nil {
+		return nil
+	}
+
+	mapped.state.isClosed = true
+	err := syscall.Munmap(mapped.mapping)
+	mapped.mapping = nil
+	mapped.records = nil
+	return err
+}
@@ -0,0 +1,222 @@
+package binaryfile
+
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+
+const BENCHMARK_SAMPLES_COUNT = 1000 * 3600
+
+
+func writeTestFile(tb testing.TB, samplesCount int) string {
+	path := filepath.Join(tb.TempDir(), "record.00")
+	if err := WriteBinaryFile(path, BAIKAL7_FMT, testHeader(), testSignals(3, samplesCount)); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+
+func TestMappedBounds(t *testing.T) {
+	mapped, err := BinaryFile{Path: writeTestFile(t, 1000)}.Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mapped.Close()
+
+	signals := testSignals(3, 1000)
+	value, err := mapped.Sample(999, 2)
+	if err != nil || value != signals[2][999] {
+		t.Fatalf("Sample(999, 2) = %d, %v, want %d", value, err, signals[2][999])
+	}
+
+	if _, err := mapped.Sample(1000, 0); !errors.Is(err, BadSignalData{}) {
+		t.Errorf("Sample index out of range: %v", err)
+	}
+	if _, err := mapped.Sample(-1, 0); !errors.Is(err, BadSignalData{}) {
+		t.Errorf("Negative sample index: %v", err)
+	}
+	if _, err := mapped.Sample(0, 3); !errors.Is(err, UnknownComponentName{}) {
+		t.Errorf("Channel index out of range: %v", err)
+	}
+	if _, err := mapped.Channel(-1); !errors.Is(err, UnknownComponentName{}) {
+		t.Errorf("Negative channel index: %v", err)
+	}
+	if _, err := mapped.Record(1000); !errors.Is(err, BadSignalData{}) {
+		t.Errorf("Record index out of range: %v", err)
+	}
+}
+
+
+func TestMappedAfterClose(t *testing.T) {
+	mapped, err := BinaryFile{Path: writeTestFile(t, 1000)}.Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := mapped.Slice(mapped.DatetimeStart, mapped.DatetimeStart.Add(time.Second / 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signal, err := view.Channel(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := view.Record(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mapped.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	signals := testSignals(3, 1000)
+	if signal[len(signal) - 1] != signals[0][len(signal) - 1] || len(record) != 12 {
+		t.Error("Copied data changed after close")
+	}
+	if _, err := view.Sample(0, 0); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Sample after close: %v", err)
+	}
+	if _, err := view.Channel(0); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Channel after close: %v", err)
+	}
+	if _, err := mapped.Record(0); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Record after close: %v", err)
+	}
+}
+
+
+func TestMappedWithRecords(t *testing.T) {
+	mapped, err := BinaryFile{Path: writeTestFile(t, 1000)}.Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := mapped.Slice(mapped.DatetimeStart.Add(time.Second / 10), mapped.DatetimeStart.Add(time.Second / 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signals := testSignals(3, 1000)
+	err = view.WithRecords(func(records []byte) error {
+		if len(records) != 400 * 12 {
+			t.Fatalf("Records size %d, want %d", len(records), 400 * 12)
+		}
+		for i := 0; i < 400; i++ {
+			for j := range signals {
+				value := int32(binary.LittleEndian.Uint32(records[12 * i + 4 * j:]))
+				if value != signals[j][100 + i] {
+					t.Fatalf("Record %d channel %d is %d, want %d", i, j, value, signals[j][100 + i])
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callbackErr := errors.New("callback error")
+	if err := view.WithRecords(func([]byte) error { return callbackErr }); err != callbackErr {
+		t.Errorf("Callback error is not returned: %v", err)
+	}
+
+	if err := mapped.Close(); err != nil {
+		t.Fatal(err)
+	}
+	isCalled := false
+	err = view.WithRecords(func([]byte) error {
+		isCalled = true
+		return nil
+	})
+	if !errors.Is(err, os.ErrClosed) || isCalled {
+		t.Errorf("WithRecords after close: %v", err)
+	}
+}
+
+
+func BenchmarkMappedWithRecords(b *testing.B) {
+	mapped, err := BinaryFile{Path: writeTestFile(b, BENCHMARK_SAMPLES_COUNT)}.Map()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mapped.Close()
+
+	b.SetBytes(4 * BENCHMARK_SAMPLES_COUNT)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sum int64
+		err := mapped.WithRecords(func(records []byte) error {
+			for offset := 4 * (i % 3); offset < len(records); offset += 12 {
+				sum += int64(int32(binary.LittleEndian.Uint32(records[offset:])))
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+
+func BenchmarkMappedSample(b *testing.B) {
+	mapped, err := BinaryFile{Path: writeTestFile(b, BENCHMARK_SAMPLES_COUNT)}.Map()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mapped.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mapped.Sample(i % BENCHMARK_SAMPLES_COUNT, i % 3); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+
+func BenchmarkMappedChannel(b *testing.B) {
+	mapped, err := BinaryFile{Path: writeTestFile(b, BENCHMARK_SAMPLES_COUNT)}.Map()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mapped.Close()
+
+	b.SetBytes(4 * BENCHMARK_SAMPLES_COUNT)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mapped.Channel(i % 3); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+
+func BenchmarkReadSignal(b *testing.B) {
+	binFile := BinaryFile{Path: writeTestFile(b, BENCHMARK_SAMPLES_COUNT)}
+	recording, err := binFile.Open()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer recording.Close()
+
+	timeStart := recording.DatetimeStart()
+	timeStop, err := recording.DatetimeStop()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(4 * BENCHMARK_SAMPLES_COUNT)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := recording.ReadSignal(timeStart, timeStop, 'X'); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
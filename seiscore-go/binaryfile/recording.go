@@ -0,0 +1,371 @@
+package binaryfile
+
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+
+type Recording struct {
+	path string
+	resampleFrequency uint16
+	isUseAvgValues bool
+	reader io.ReaderAt
+	size int64
+	closer io.Closer
+	info os.FileInfo
+	formatType string
+	header FileHeader
+	closeOnce sync.Once
+	closeErr error
+}
+
+
+func (binFile BinaryFile) OpenReader(reader io.ReaderAt, size int64) (*Recording, error) {
+	detection, err := DetectFormat(reader, size, fileExtension(binFile.Path))
+	if err != nil {
+		return nil, withPath(err, binFile.Path)
+	}
+
+	header, err := ReadHeader(reader, size, detection.FormatType)
+	if err != nil {
+		return nil, withPath(err, binFile.Path)
+	}
+
+	return &Recording{
+		path: binFile.Path,
+		resampleFrequency: binFile.ResampleFrequency,
+		isUseAvgValues: binFile.IsUseAvgValues,
+		reader: reader,
+		size: size,
+		formatType: detection.FormatType,
+		header: header}, nil
+}
+
+
+func (binFile BinaryFile) Open() (*Recording, error) {
+	if len(binFile.Path) == 0 {
+		return nil, BadFilePath{message: "Empty file path"}
+	}
+
+	file, err := os.Open(binFile.Path)
+	if err != nil {
+		return nil, fileError(err, binFile.Path)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fileError(err, binFile.Path)
+	}
+
+	recording, err := binFile.OpenReader(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	recording.closer = file
+	recording.info = info
+	return recording, nil
+}
+
+
+func OpenRecording(path string) (*Recording, error) {
+	return BinaryFile{Path: path}.Open()
+}
+
+
+func NewRecording(reader io.ReaderAt, size int64, name string) (*Recording, error) {
+	return BinaryFile{Path: name}.OpenReader(reader, size)
+}
+
+func (recording *Recording) Close() error {
+	recording.closeOnce.Do(func() {
+		if recording.closer != nil {
+			recording.closeErr = recording.closer.Close()
+		}
+	})
+	return recording.closeErr
+}
+
+func (recording *Recording) Path() string {
+	return recording.path
+}
+
+func (recording *Recording) Size() int64 {
+	return recording.size
+}
+
+func (recording *Recording) Stat() os.FileInfo {
+	return recording.info
+}
+
+func (recording *Recording) FormatType() string {
+	return recording.formatType
+}
+
+func (recording *Recording) Header() FileHeader {
+	return recording.header
+}
+
+func (recording *Recording) ChannelNames() []string {
+	return recording.header.ChannelNames()
+}
+
+func (recording *Recording) getResampleFrequency() (uint16, error) {
+	header := recording.header
+	switch freq := recording.resampleFrequency; {
+	case freq < 0:
+		return 0, InvalidResampleFrequency{message: fmt.Sprint(freq)}
+	case freq == 0:
+		return header.Frequency, nil
+	case header.Frequency % freq == 0:
+		return freq, nil
+	default:
+		return 0, InvalidResampleFrequency{message: fmt.Sprint(freq)}
+	}
+}
+
+func (recording *Recording) GetResampleFrequency() (uint16, error) {
+	resampleFrequency, err := recording.getResampleFrequency()
+	return resampleFrequency, withPath(err, recording.path)
+}
+
+func (recording *Recording) discreteCount() (uint64, error) {
+	header := recording.header
+	headerSize := headerMemorySize(int(header.ChannelsCount))
+
+	size := recording.size
+	if size < int64(headerSize) {
+		return 0, BadHeaderData{message: "File is shorter than header"}
+	}
+	discreteCount := (uint64(size) - uint64(headerSize)) / (uint64(header.ChannelsCount) * uint64(UnsignedIntType{}.ByteSize()))
+	return discreteCount, nil
+}
+
+func (recording *Recording) SamplesCount() (uint64, error) {
+	discreteCount, err := recording.discreteCount()
+	return discreteCount, withPath(err, recording.path)
+}
+
+func (recording *Recording) secondsDuration() (float64, error) {
+	discreteCount, err := recording.discreteCount()
+	if err != nil {
+		return 0, err
+	}
+
+	frequency := recording.header.Frequency
+	accuracy := uint8(math.Log10(float64(frequency)))
+
+	deltaSeconds := truncate(float64(discreteCount) / float64(frequency), accuracy)
+	return deltaSeconds, nil
+}
+
+func (recording *Recording) DatetimeStart() time.Time {
+	offset := 0
+	if recording.formatType == SIGMA_FMT {
+		offset = SIGMA_SECONDS_OFFSET
+	}
+	return recording.header.DatetimeStart.Add(time.Second * time.Duration(offset))
+}
+
+func (recording *Recording) DatetimeStop() (time.Time, error) {
+	secondsDuration, err := recording.secondsDuration()
+	if err != nil {
+		return time.Time{}, withPath(err, recording.path)
+	}
+
+	nanosecondsDuration := secondsDuration * 1e9
+	return recording.DatetimeStart().Add(time.Nanosecond * time.Duration(nanosecondsDuration)), nil
+}
+
+func (recording *Recording) FileInfo() (FileInfo, error) {
+	timeStop, err := recording.DatetimeStop()
+	if err != nil {
+		return FileInfo{}, withPath(err, recording.path)
+	}
+
+	return FileInfo{
+		Path: recording.path,
+		FormatType: recording.formatType,
+		Channels: recording.header.ChannelNames(),
+		Frequency: recording.header.Frequency,
+		TimeStart: recording.DatetimeStart(),
+		TimeStop: timeStop,
+		Coordinate: recording.header.Coordinate}, nil
+}
+
+func (recording *Recording) checkReadDatetimeStart(datetime time.Time) error {
+	datetimeStop, err := recording.DatetimeStop()
+	if err != nil {
+		return err
+	}
+
+	secondsDiff := datetime.Sub(recording.DatetimeStart()).Seconds()
+	if secondsDiff < 0 {
+		return InvalidDatetimeValue{message: "Reading time start is less than recording time start"}
+	}
+
+	secondsDiff = datetimeStop.Sub(datetime).Seconds()
+	if secondsDiff <= 0 {
+		return InvalidDatetimeValue{message: "Reading time start is more than recording time stop"}
+	}
+	return nil
+}
+
+func (recording *Recording) checkReadDatetimeStop(datetime time.Time) error {
+	datetimeStop, err := recording.DatetimeStop()
+	if err != nil {
+		return err
+	}
+
+	secondsDiff := datetime.Sub(recording.DatetimeStart()).Seconds()
+	if secondsDiff <= 0 {
+		return InvalidDatetimeValue{message: "Reading time stop is less than recording time start"}
+	}
+
+	secondsDiff = datetimeStop.Sub(datetime).Seconds()
+	if secondsDiff < 0 {
+		return InvalidDatetimeValue{message: "Reading time stop is more than recording time stop"}
+	}
+	return nil
+}
+
+func (recording *Recording) IsGoodReadDatetimeStart(datetime time.Time) (bool, error) {
+	err := recording.checkReadDatetimeStart(datetime)
+	return err == nil, withPath(err, recording.path)
+}
+
+func (recording *Recording) IsGoodReadDatetimeStop(datetime time.Time) (bool, error) {
+	err := recording.checkReadDatetimeStop(datetime)
+	return err == nil, withPath(err, recording.path)
+}
+
+func (recording *Recording) resampleParameter() (uint16, error) {
+	resampleFrequency, err := recording.getResampleFrequency()
+	if err != nil {
+		return 0, err
+	}
+	return recording.header.Frequency / resampleFrequency, nil
+}
+
+func (recording *Recording) getIndexesInterval(datetimeStart time.Time, datetimeStop time.Time) ([2]uint64, error) {
+	defaultValue := [2]uint64{0, 0}
+	if !datetimeStop.After(datetimeStart) {
+		return defaultValue, InvalidDatetimeValue{message: "Reading time stop is not after reading time start"}
+	}
+
+	if err := recording.checkReadDatetimeStart(datetimeStart); err != nil {
+		return defaultValue, err
+	}
+
+	if err := recording.checkReadDatetimeStop(datetimeStop); err != nil {
+		return defaultValue, err
+	}
+
+	resampleParameter, err := recording.resampleParameter()
+	if err != nil {
+		return defaultValue, err
+	}
+
+	originFrequency := recording.header.Frequency
+	recordingDatetimeStart := recording.DatetimeStart()
+
+	secondsDiff := datetimeStart.Sub(recordingDatetimeStart).Seconds()
+	startIndex := uint64(math.Round(secondsDiff * float64(originFrequency)))
+
+	secondsDiff = datetimeStop.Sub(recordingDatetimeStart).Seconds()
+	stopIndex := uint64(math.Round(secondsDiff * float64(originFrequency)))
+
+	signalLength := (stopIndex - startIndex) / uint64(resampleParameter)
+	stopIndex = startIndex + signalLength * uint64(resampleParameter)
+
+	return [2]uint64{startIndex, stopIndex}, nil
+}
+
+func (recording *Recording) channelIndexes(components []string) ([]int, error) {
+	if len(components) == 0 {
+		components = recording.header.ChannelNames()
+	}
+
+	channelIndexes := make([]int, len(components))
+	for i, component := range components {
+		channelIndex, err := recording.header.ChannelIndex(component)
+		if err != nil {
+			return nil, err
+		}
+		channelIndexes[i] = channelIndex
+	}
+	return channelIndexes, nil
+}
+
+func (recording *Recording) readSignals(timeStart time.Time, timeStop time.Time, channelIndexes []int) ([][]int32, error) {
+	stream, err := recording.newSignalStream(timeStart, timeStop, channelIndexes, 0)
+	if err != nil {
+		return make([][]int32, len(channelIndexes)), err
+	}
+	return stream.readAll()
+}
+
+func (recording *Recording) readSignal(timeStart time.Time, timeStop time.Time, channelIndex int) ([]int32, error) {
+	signals, err := recording.readSignals(timeStart, timeStop, []int{channelIndex})
+	return signals[0], err
+}
+
+func (recording *Recording) ReadChannel(timeStart time.Time, timeStop time.Time, channelIndex int) ([]int32, error) {
+	signal, err := recording.readSignal(timeStart, timeStop, channelIndex)
+	if err != nil {
+		return []int32{}, withPath(err, recording.path)
+	}
+
+	if recording.isUseAvgValues {
+		removeAverage(signal)
+	}
+	return signal, nil
+}
+
+func (recording *Recording) ReadChannelByName(timeStart time.Time, timeStop time.Time, channelName string) ([]int32, error) {
+	channelIndex, err := recording.header.ChannelIndex(channelName)
+	if err != nil {
+		return []int32{}, withPath(err, recording.path)
+	}
+	return recording.ReadChannel(timeStart, timeStop, channelIndex)
+}
+
+func (recording *Recording) ReadSignal(timeStart time.Time, timeStop time.Time, component rune) ([]int32, error) {
+	return recording.ReadChannelByName(timeStart, timeStop, string(component))
+}
+
+func (recording *Recording) ReadSignals(timeStart time.Time, timeStop time.Time, components []string) (MultichannelTrace, error) {
+	channelIndexes, err := recording.channelIndexes(components)
+	if err != nil {
+		return MultichannelTrace{}, withPath(err, recording.path)
+	}
+
+	stream, err := recording.newSignalStream(timeStart, timeStop, channelIndexes, 0)
+	if err != nil {
+		return MultichannelTrace{}, withPath(err, recording.path)
+	}
+
+	signals, err := stream.readAll()
+	if err != nil {
+		return MultichannelTrace{}, withPath(err, recording.path)
+	}
+
+	if recording.isUseAvgValues {
+		for _, signal := range signals {
+			removeAverage(signal)
+		}
+	}
+
+	return MultichannelTrace{
+		Channels: stream.Channels(),
+		Signals: signals,
+		Frequency: stream.Frequency(),
+		DatetimeStart: stream.DatetimeStart()}, nil
+}
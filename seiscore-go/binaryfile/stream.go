@@ -0,0 +1,299 @@
+package binaryfile
+
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+
+type SignalBlock struct {
+	DatetimeStart time.Time
+	Frequency uint16
+	Channels []string
+	Signals [][]int32
+}
+
+func (block SignalBlock) SamplesCount() int {
+	if len(block.Signals) == 0 {
+		return 0
+	}
+	return len(block.Signals[0])
+}
+
+
+type SignalStream struct {
+	reader io.Reader
+	path string
+	offset int64
+	owner *Recording
+	buffer []byte
+	channels []string
+	channelIndexes []int
+	averages []int32
+	oneRecordBytesSize int
+	resampleParameter int
+	blockSize int
+	frequency uint16
+	datetimeStart time.Time
+	remainingSamplesCount int
+	emittedSamplesCount int
+	block SignalBlock
+	err error
+}
+
+
+func (recording *Recording) newSignalStream(timeStart time.Time, timeStop time.Time, channelIndexes []int, blockSize int) (*SignalStream, error) {
+	header := recording.header
+	channelsCount := int(header.ChannelsCount)
+	channelNames := header.ChannelNames()
+	channels := make([]string, len(channelIndexes))
+	for i, channelIndex := range channelIndexes {
+		if channelIndex < 0 || channelIndex >= channelsCount {
+			return nil, UnknownComponentName{message: fmt.Sprint(channelIndex)}
+		}
+		channels[i] = channelNames[channelIndex]
+	}
+
+	indexes, err := recording.getIndexesInterval(timeStart, timeStop)
+	if err != nil {
+		return nil, err
+	}
+
+	resampleParameter, err := recording.resampleParameter()
+	if err != nil {
+		return nil, err
+	}
+
+	oneRecordBytesSize := 4 * channelsCount
+	if blockSize <= 0 {
+		blockSize = BASE_MEMORY_BLOCK_SIZE / (oneRecordBytesSize * int(resampleParameter))
+		if blockSize == 0 {
+			blockSize = 1
+		}
+	}
+
+	offsetSize := headerMemorySize(channelsCount) + int(indexes[0]) * oneRecordBytesSize
+	signalBytesSize := int(indexes[1] - indexes[0]) * oneRecordBytesSize
+
+	offset := time.Duration(float64(indexes[0]) / float64(header.Frequency) * 1e9)
+	return &SignalStream{
+		reader: io.NewSectionReader(recording.reader, int64(offsetSize), int64(signalBytesSize)),
+		path: recording.path,
+		offset: int64(offsetSize),
+		buffer: make([]byte, blockSize * int(resampleParameter) * oneRecordBytesSize),
+		channels: channels,
+		channelIndexes: channelIndexes,
+		oneRecordBytesSize: oneRecordBytesSize,
+		resampleParameter: int(resampleParameter),
+		blockSize: blockSize,
+		frequency: header.Frequency / resampleParameter,
+		datetimeStart: recording.DatetimeStart().Add(offset),
+		remainingSamplesCount: int(indexes[1] - indexes[0]) / int(resampleParameter)}, nil
+}
+
+func (recording *Recording) signalAverages(timeStart time.Time, timeStop time.Time, channelIndexes []int, blockSize int) ([]int32, error) {
+	stream, err := recording.newSignalStream(timeStart, timeStop, channelIndexes, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make([]int64, len(channelIndexes))
+	count := int64(0)
+	for stream.Next() {
+		block := stream.Block()
+		for i, signal := range block.Signals {
+			for _, value := range signal {
+				sums[i] += int64(value)
+			}
+		}
+		count += int64(block.SamplesCount())
+	}
+	if stream.Err() != nil {
+		return nil, stream.Err()
+	}
+
+	averages := make([]int32, len(channelIndexes))
+	for i := range averages {
+		if count > 0 {
+			averages[i] = int32(sums[i] / count)
+		}
+	}
+	return averages, nil
+}
+
+func (recording *Recording) NewSignalStream(timeStart time.Time, timeStop time.Time, components []string, blockSize int) (*SignalStream, error) {
+	channelIndexes, err := recording.channelIndexes(components)
+	if err != nil {
+		return nil, withPath(err, recording.path)
+	}
+
+	var averages []int32
+	if recording.isUseAvgValues {
+		averages, err = recording.signalAverages(timeStart, timeStop, channelIndexes, blockSize)
+		if err != nil {
+			return nil, withPath(err, recording.path)
+		}
+	}
+
+	stream, err := recording.newSignalStream(timeStart, timeStop, channelIndexes, blockSize)
+	if err != nil {
+		return nil, withPath(err, recording.path)
+	}
+	stream.averages = averages
+	return stream, nil
+}
+
+
+func (binFile BinaryFile) NewSignalStream(timeStart time.Time, timeStop time.Time, components []string, blockSize int) (*SignalStream, error) {
+	recording, err := binFile.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := recording.NewSignalStream(timeStart, timeStop, components, blockSize)
+	if err != nil {
+		recording.Close()
+		return nil, err
+	}
+	stream.owner = recording
+	return stream, nil
+}
+
+func (stream *SignalStream) Next() bool {
+	if stream.err != nil || stream.remainingSamplesCount == 0 {
+		return false
+	}
+
+	samplesCount := stream.blockSize
+	if stream.remainingSamplesCount < samplesCount {
+		samplesCount = stream.remainingSamplesCount
+	}
+
+	bytesCount := samplesCount * stream.resampleParameter * stream.oneRecordBytesSize
+	readCount, err := io.ReadFull(stream.reader, stream.buffer[:bytesCount])
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = BadSignalData{message: "Unexpected EOF"}
+		}
+		stream.err = ReadError{
+			Path: stream.path,
+			Offset: stream.offset + int64(readCount),
+			Field: "signal data",
+			Err: err}
+		return false
+	}
+	stream.offset += int64(bytesCount)
+
+	signals := make([][]int32, len(stream.channelIndexes))
+	for i := range signals {
+		signals[i] = make([]int32, samplesCount)
+	}
+
+	sumValues := make([]int64, len(stream.channelIndexes))
+	for sampleIndex := 0; sampleIndex < samplesCount; sampleIndex++ {
+		for k := 0; k < stream.resampleParameter; k++ {
+			recordOffset := (sampleIndex * stream.resampleParameter + k) * stream.oneRecordBytesSize
+			for j, channelIndex := range stream.channelIndexes {
+				index := recordOffset + 4 * channelIndex
+				sumValues[j] += int64(int32(binary.LittleEndian.Uint32(stream.buffer[index:])))
+			}
+		}
+
+		for j := range stream.channelIndexes {
+			value := int32(sumValues[j] / int64(stream.resampleParameter))
+			if stream.averages != nil {
+				value -= stream.averages[j]
+			}
+			signals[j][sampleIndex] = value
+			sumValues[j] = 0
+		}
+	}
+
+	offset := float64(stream.emittedSamplesCount) / float64(stream.frequency) * 1e9
+	stream.block = SignalBlock{
+		DatetimeStart: stream.datetimeStart.Add(time.Duration(offset)),
+		Frequency: stream.frequency,
+		Channels: stream.channels,
+		Signals: signals}
+
+	stream.emittedSamplesCount += samplesCount
+	stream.remainingSamplesCount -= samplesCount
+	return true
+}
+
+func (stream *SignalStream) readAll() ([][]int32, error) {
+	signals := make([][]int32, len(stream.channelIndexes))
+	for i := range signals {
+		signals[i] = make([]int32, 0, stream.remainingSamplesCount)
+	}
+
+	for stream.Next() {
+		for i, signal := range stream.block.Signals {
+			signals[i] = append(signals[i], signal...)
+		}
+	}
+
+	if stream.err != nil {
+		return make([][]int32, len(stream.channelIndexes)), stream.err
+	}
+	return signals, nil
+}
+
+func (stream *SignalStream) Block() SignalBlock {
+	return stream.block
+}
+
+func (stream *SignalStream) Err() error {
+	return stream.err
+}
+
+func (stream *SignalStream) DatetimeStart() time.Time {
+	return stream.datetimeStart
+}
+
+func (stream *SignalStream) Frequency() uint16 {
+	return stream.frequency
+}
+
+func (stream *SignalStream) Channels() []string {
+	return stream.channels
+}
+
+func (stream *SignalStream) Close() error {
+	if stream.owner == nil {
+		return nil
+	}
+	return stream.owner.Close()
+}
+
+func (stream *SignalStream) Blocks(ctx context.Context) <-chan SignalBlock {
+	blocks := make(chan SignalBlock)
+	go func() {
+		defer close(blocks)
+		defer stream.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				stream.err = ctx.Err()
+				return
+			default:
+			}
+
+			if !stream.Next() {
+				return
+			}
+
+			select {
+			case blocks <- stream.Block():
+			case <-ctx.Done():
+				stream.err = ctx.Err()
+				return
+			}
+		}
+	}()
+	return blocks
+}
@@ -0,0 +1,36 @@
+package binaryfile
+
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+
+func TestReversedReadWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "record.00")
+	if err := WriteBinaryFile(path, BAIKAL7_FMT, testHeader(), testSignals(3, 5000)); err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := OpenRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recording.Close()
+
+	start := recording.DatetimeStart()
+	if _, err := recording.ReadChannel(start.Add(3 * time.Second), start.Add(time.Second), 0); !errors.Is(err, InvalidDatetimeValue{}) {
+		t.Errorf("Reversed window read error %v, want InvalidDatetimeValue", err)
+	}
+	if _, err := recording.ReadChannel(start.Add(time.Second), start.Add(time.Second), 0); !errors.Is(err, InvalidDatetimeValue{}) {
+		t.Errorf("Empty window read error %v, want InvalidDatetimeValue", err)
+	}
+
+	_, err = recording.NewSignalStream(start.Add(3 * time.Second), start.Add(time.Second), nil, 0)
+	if !errors.Is(err, InvalidDatetimeValue{}) {
+		t.Errorf("Reversed window stream error %v, want InvalidDatetimeValue", err)
+	}
+}
@@ -0,0 +1,35 @@
+package binaryfile
+
+
+import (
+	"time"
+)
+
+
+type MultichannelTrace struct {
+	Channels []string
+	Signals [][]int32
+	Frequency uint16
+	DatetimeStart time.Time
+}
+
+func (trace MultichannelTrace) Signal(channelName string) ([]int32, error) {
+	for i, name := range trace.Channels {
+		if name == channelName {
+			return trace.Signals[i], nil
+		}
+	}
+	return []int32{}, UnknownComponentName{message: channelName}
+}
+
+func (trace MultichannelTrace) SamplesCount() int {
+	if len(trace.Signals) == 0 {
+		return 0
+	}
+	return len(trace.Signals[0])
+}
+
+func (trace MultichannelTrace) DatetimeStop() time.Time {
+	duration := float64(trace.SamplesCount()) / float64(trace.Frequency) * 1e9
+	return trace.DatetimeStart.Add(time.Duration(duration))
+}
@@ -0,0 +1,358 @@
+package binaryfile
+
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+
+const (
+	SEVERITY_INFO, SEVERITY_WARNING, SEVERITY_ERROR = "info", "warning", "error"
+)
+
+var severityLevels = map[string]int{
+	SEVERITY_INFO: 1,
+	SEVERITY_WARNING: 2,
+	SEVERITY_ERROR: 3,
+}
+
+
+type ValidationIssue struct {
+	Severity string
+	Field string
+	Offset int64
+	Message string
+}
+
+func (issue ValidationIssue) String() string {
+	return fmt.Sprintf("%s: %s at offset %d: %s", issue.Severity, issue.Field, issue.Offset, issue.Message)
+}
+
+
+type ValidationReport struct {
+	Path string
+	FormatType string
+	Size int64
+	Issues []ValidationIssue
+}
+
+func (report *ValidationReport) add(severity string, field string, offset int64, message string) {
+	report.Issues = append(report.Issues, ValidationIssue{
+		Severity: severity,
+		Field: field,
+		Offset: offset,
+		Message: message})
+}
+
+func (report ValidationReport) IssuesBySeverity(severity string) []ValidationIssue {
+	issues := []ValidationIssue{}
+	for _, issue := range report.Issues {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (report ValidationReport) MaxSeverity() string {
+	maxSeverity := ""
+	for _, issue := range report.Issues {
+		if severityLevels[issue.Severity] > severityLevels[maxSeverity] {
+			maxSeverity = issue.Severity
+		}
+	}
+	return maxSeverity
+}
+
+func (report ValidationReport) IsValid() bool {
+	return len(report.IssuesBySeverity(SEVERITY_ERROR)) == 0
+}
+
+
+type rawHeaderValues struct {
+	channelsCount uint16
+	channelsCountOffset int64
+	frequency float64
+	frequencyOffset int64
+	latitude float64
+	longitude float64
+	coordinateOffset int64
+	coordinateErr error
+	datetimeStart time.Time
+	datetimeOffset int64
+	datetimeErr error
+	gpsFlagsOffset int64
+	gpsValidFlag uint16
+}
+
+
+func rawBaikal7Values(header []byte) rawHeaderValues {
+	values := rawHeaderValues{
+		channelsCount: binary.LittleEndian.Uint16(header[0:]),
+		frequency: float64(binary.LittleEndian.Uint16(header[22:])),
+		frequencyOffset: 22,
+		latitude: math.Float64frombits(binary.LittleEndian.Uint64(header[72:])),
+		longitude: math.Float64frombits(binary.LittleEndian.Uint64(header[80:])),
+		coordinateOffset: 72,
+		datetimeOffset: 104,
+		gpsFlagsOffset: 14,
+		gpsValidFlag: binary.LittleEndian.Uint16(header[14:])}
+
+	timeBegin := binary.LittleEndian.Uint64(header[104:])
+	if timeBegin == 0 {
+		values.datetimeErr = BadHeaderData{message: "Time begin is zero"}
+	} else {
+		values.datetimeStart = getDatetimeStartBaikal7(timeBegin)
+	}
+	return values
+}
+
+
+func rawBaikal8Values(header []byte) rawHeaderValues {
+	values := rawHeaderValues{
+		channelsCount: binary.LittleEndian.Uint16(header[0:]),
+		frequencyOffset: 48,
+		latitude: math.Float64frombits(binary.LittleEndian.Uint64(header[72:])),
+		longitude: math.Float64frombits(binary.LittleEndian.Uint64(header[80:])),
+		coordinateOffset: 72,
+		datetimeOffset: 6,
+		gpsFlagsOffset: 14,
+		gpsValidFlag: binary.LittleEndian.Uint16(header[14:])}
+
+	sampleInterval := math.Float64frombits(binary.LittleEndian.Uint64(header[48:]))
+	if sampleInterval > 0 {
+		values.frequency = 1 / sampleInterval
+	} else {
+		values.frequency = math.NaN()
+	}
+
+	day := int(binary.LittleEndian.Uint16(header[6:]))
+	month := int(binary.LittleEndian.Uint16(header[8:]))
+	year := int(binary.LittleEndian.Uint16(header[10:]))
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	secondsOfDay := math.Float64frombits(binary.LittleEndian.Uint64(header[56:]))
+	switch {
+	case date.Day() != day || int(date.Month()) != month || date.Year() != year:
+		values.datetimeErr = BadHeaderData{message: "Invalid date in header"}
+	case math.IsNaN(secondsOfDay) || secondsOfDay < 0 || secondsOfDay >= 86400:
+		values.datetimeOffset = 56
+		values.datetimeErr = BadHeaderData{message: "Invalid seconds of day in header"}
+	default:
+		values.datetimeStart = date.Add(time.Duration(secondsOfDay * 1e9))
+	}
+	return values
+}
+
+
+func rawSigmaValues(header []byte) rawHeaderValues {
+	values := rawHeaderValues{
+		channelsCount: binary.LittleEndian.Uint16(header[12:]),
+		channelsCountOffset: 12,
+		frequency: float64(binary.LittleEndian.Uint16(header[24:])),
+		frequencyOffset: 24,
+		coordinateOffset: 40,
+		datetimeOffset: 60,
+		gpsFlagsOffset: 28,
+		gpsValidFlag: binary.LittleEndian.Uint16(header[28:])}
+
+	coordinate, err := getCoordinatesSigma(string(header[48:57]), string(header[40:48]))
+	values.latitude, values.longitude, values.coordinateErr = coordinate.Latitude, coordinate.Longitude, err
+
+	dateNum := binary.LittleEndian.Uint32(header[60:])
+	timeNum := binary.LittleEndian.Uint32(header[64:])
+	values.datetimeStart, values.datetimeErr = getDatetimeStartSigma(dateNum, timeNum)
+	return values
+}
+
+
+func validateFrequency(values rawHeaderValues, report *ValidationReport) {
+	frequency := values.frequency
+	switch {
+	case math.IsNaN(frequency) || frequency <= 0:
+		report.add(SEVERITY_ERROR, "frequency", values.frequencyOffset, "Frequency is zero or undefined")
+	case !isPlausibleFrequency(frequency):
+		report.add(SEVERITY_ERROR, "frequency", values.frequencyOffset, fmt.Sprintf("Implausible frequency %g Hz", frequency))
+	case math.Abs(frequency - math.Round(frequency)) > 1e-6:
+		report.add(SEVERITY_WARNING, "frequency", values.frequencyOffset, fmt.Sprintf("Frequency %g Hz is not a whole number", frequency))
+	}
+}
+
+
+func validateCoordinate(values rawHeaderValues, report *ValidationReport) {
+	latitude, longitude := values.latitude, values.longitude
+	switch {
+	case values.coordinateErr != nil:
+		report.add(SEVERITY_ERROR, "coordinates", values.coordinateOffset, values.coordinateErr.Error())
+	case math.IsNaN(latitude) || math.IsNaN(longitude) || math.IsInf(latitude, 0) || math.IsInf(longitude, 0):
+		report.add(SEVERITY_ERROR, "coordinates", values.coordinateOffset, "Coordinate is NaN or infinite")
+	case !isPlausibleCoordinate(latitude, longitude):
+		report.add(SEVERITY_ERROR, "coordinates", values.coordinateOffset, fmt.Sprintf("Coordinate %g, %g is out of range", latitude, longitude))
+	case latitude == 0 && longitude == 0:
+		report.add(SEVERITY_WARNING, "coordinates", values.coordinateOffset, "Coordinate is zero")
+	}
+}
+
+
+func validateDatetime(values rawHeaderValues, now time.Time, report *ValidationReport) {
+	datetime := values.datetimeStart
+	switch {
+	case values.datetimeErr != nil:
+		report.add(SEVERITY_ERROR, "datetime", values.datetimeOffset, values.datetimeErr.Error())
+	case datetime.Before(baikal7Epoch):
+		report.add(SEVERITY_ERROR, "datetime", values.datetimeOffset, fmt.Sprintf("Start time %s is before 1980", datetime.Format(time.RFC3339)))
+	case datetime.After(now):
+		report.add(SEVERITY_ERROR, "datetime", values.datetimeOffset, fmt.Sprintf("Start time %s is in the future", datetime.Format(time.RFC3339)))
+	}
+}
+
+
+func validateChannelHeaders(reader io.ReaderAt, channelsCount uint16, report *ValidationReport) {
+	fields := &fieldReader{reader: reader}
+	channels := readChannelHeaders(fields, channelsCount)
+	if fields.err != nil {
+		report.add(SEVERITY_ERROR, "channel headers", MAIN_HEADER_SIZE, fields.err.Error())
+		return
+	}
+
+	emptyCount := 0
+	names := map[string]bool{}
+	for i, channel := range channels {
+		offset := int64(MAIN_HEADER_SIZE + CHANNEL_HEADER_SIZE * i)
+		if channel == (ChannelHeader{}) {
+			emptyCount++
+			continue
+		}
+
+		if len(channel.Name) != 0 {
+			if names[channel.Name] {
+				report.add(SEVERITY_WARNING, "channel name", offset + 4, fmt.Sprintf("Duplicate channel name %q", channel.Name))
+			}
+			names[channel.Name] = true
+		}
+	}
+
+	if emptyCount != 0 && emptyCount != len(channels) {
+		message := fmt.Sprintf("Header declares %d channels but only %d channel headers are filled", channelsCount, len(channels) - emptyCount)
+		report.add(SEVERITY_WARNING, "channels count", 0, message)
+	}
+}
+
+
+func validateDataSize(size int64, channelsCount uint16, report *ValidationReport) {
+	headerSize := int64(headerMemorySize(int(channelsCount)))
+	dataSize := size - headerSize
+	recordSize := 4 * int64(channelsCount)
+	switch {
+	case dataSize == 0:
+		report.add(SEVERITY_WARNING, "data", headerSize, "File contains no signal data")
+	case dataSize % 4 != 0:
+		message := fmt.Sprintf("Data size %d is not a whole number of records of %d bytes", dataSize, recordSize)
+		report.add(SEVERITY_ERROR, "data", headerSize, message)
+	case dataSize % recordSize != 0:
+		partialSize := dataSize % recordSize
+		message := fmt.Sprintf("Trailing partial record of %d bytes", partialSize)
+		report.add(SEVERITY_WARNING, "data", size - partialSize, message)
+	}
+}
+
+
+func validate(reader io.ReaderAt, size int64, formatType string, now time.Time) ValidationReport {
+	report := ValidationReport{FormatType: formatType, Size: size}
+	if size < MAIN_HEADER_SIZE {
+		report.add(SEVERITY_ERROR, "header", 0, "File is shorter than main header")
+		return report
+	}
+
+	header := make([]byte, MAIN_HEADER_SIZE)
+	if _, err := reader.ReadAt(header, 0); err != nil && err != io.EOF {
+		report.add(SEVERITY_ERROR, "header", 0, err.Error())
+		return report
+	}
+
+	var values rawHeaderValues
+	switch formatType {
+	case BAIKAL7_FMT:
+		values = rawBaikal7Values(header)
+	case BAIKAL8_FMT:
+		values = rawBaikal8Values(header)
+	case SIGMA_FMT:
+		values = rawSigmaValues(header)
+	default:
+		report.add(SEVERITY_ERROR, "format", 0, "Unknown format type")
+		return report
+	}
+
+	validateFrequency(values, &report)
+	validateCoordinate(values, &report)
+	validateDatetime(values, now, &report)
+	if values.gpsValidFlag == 0 {
+		report.add(SEVERITY_INFO, "GPS valid flag", values.gpsFlagsOffset, "GPS position is not valid")
+	}
+
+	channelsCount := values.channelsCount
+	if !isPlausibleChannelsCount(channelsCount) {
+		message := fmt.Sprintf("Implausible channels count %d", channelsCount)
+		report.add(SEVERITY_ERROR, "channels count", values.channelsCountOffset, message)
+		return report
+	}
+
+	if size < int64(headerMemorySize(int(channelsCount))) {
+		message := fmt.Sprintf("File is shorter than header of %d channels", channelsCount)
+		report.add(SEVERITY_ERROR, "channels count", values.channelsCountOffset, message)
+		return report
+	}
+
+	validateChannelHeaders(reader, channelsCount, &report)
+	validateDataSize(size, channelsCount, &report)
+	return report
+}
+
+
+func Validate(reader io.ReaderAt, size int64, formatType string) ValidationReport {
+	return validate(reader, size, formatType, time.Now())
+}
+
+
+func ValidateFile(path string) (ValidationReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ValidationReport{}, fileError(err, path)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return ValidationReport{}, fileError(err, path)
+	}
+
+	report := ValidationReport{Path: path, Size: info.Size()}
+	detections, err := DetectFormats(file, info.Size(), fileExtension(path))
+	if err != nil || len(detections) == 0 {
+		report.add(SEVERITY_ERROR, "format", 0, "Unknown file format")
+		return report, nil
+	}
+
+	detection := detections[0]
+	report = Validate(file, info.Size(), detection.FormatType)
+	report.Path = path
+	if detection.Confidence < MIN_DETECTION_CONFIDENCE {
+		message := fmt.Sprintf("Format %s detected with low confidence %.2f", detection.FormatType, detection.Confidence)
+		report.add(SEVERITY_WARNING, "format", 0, message)
+	}
+	return report, nil
+}
+
+func (binFile BinaryFile) Validate() (ValidationReport, error) {
+	return ValidateFile(binFile.Path)
+}
+
+func (recording *Recording) Validate() ValidationReport {
+	report := Validate(recording.reader, recording.size, recording.formatType)
+	report.Path = recording.path
+	return report
+}
@@ -0,0 +1,77 @@
+package binaryfile
+
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+
+func TestValidateBadHeader(t *testing.T) {
+	header := fullTestHeader(t, BAIKAL7_FMT)
+	header.Gps.ValidFlag = 0
+	header.Channels[1].Name = "Z"
+	path := filepath.Join(t.TempDir(), "record.00")
+	if err := WriteBinaryFile(path, BAIKAL7_FMT, header, testSignals(3, 1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.LittleEndian.PutUint16(data[22:], 0)
+	binary.LittleEndian.PutUint64(data[72:], math.Float64bits(95))
+	data = append(data, 0, 0, 0, 0)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := validate(bytes.NewReader(data), int64(len(data)), BAIKAL7_FMT, now)
+
+	expected := []ValidationIssue{
+		{Severity: SEVERITY_ERROR, Field: "frequency", Offset: 22},
+		{Severity: SEVERITY_ERROR, Field: "coordinates", Offset: 72},
+		{Severity: SEVERITY_INFO, Field: "GPS valid flag", Offset: 14},
+		{Severity: SEVERITY_WARNING, Field: "channel name", Offset: MAIN_HEADER_SIZE + CHANNEL_HEADER_SIZE + 4},
+		{Severity: SEVERITY_WARNING, Field: "data", Offset: int64(len(data) - 4)},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("%d issues, want %d: %v", len(report.Issues), len(expected), report.Issues)
+	}
+	for i, issue := range report.Issues {
+		issue.Message = ""
+		if issue != expected[i] {
+			t.Errorf("Issue %d is %+v, want %+v", i, issue, expected[i])
+		}
+	}
+
+	if report.IsValid() || report.MaxSeverity() != SEVERITY_ERROR {
+		t.Errorf("Report is valid %t with max severity %q", report.IsValid(), report.MaxSeverity())
+	}
+	counts := map[string]int{SEVERITY_INFO: 1, SEVERITY_WARNING: 2, SEVERITY_ERROR: 2}
+	for severity, count := range counts {
+		if issues := report.IssuesBySeverity(severity); len(issues) != count {
+			t.Errorf("%d %s issues, want %d", len(issues), severity, count)
+		}
+	}
+}
+
+
+func TestValidateGoodHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "record.00")
+	if err := WriteBinaryFile(path, BAIKAL7_FMT, fullTestHeader(t, BAIKAL7_FMT), testSignals(3, 1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ValidateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 0 || !report.IsValid() || report.MaxSeverity() != "" {
+		t.Errorf("Good header has issues: %v", report.Issues)
+	}
+}
@@ -0,0 +1,263 @@
+package binaryfile
+
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+
+func putUnsignedShort(buffer []byte, offset int, value uint16) {
+	binary.LittleEndian.PutUint16(buffer[offset:], value)
+}
+
+
+func putUnsignedInt(buffer []byte, offset int, value uint32) {
+	binary.LittleEndian.PutUint32(buffer[offset:], value)
+}
+
+
+func putLong(buffer []byte, offset int, value uint64) {
+	binary.LittleEndian.PutUint64(buffer[offset:], value)
+}
+
+
+func putDouble(buffer []byte, offset int, value float64) {
+	binary.LittleEndian.PutUint64(buffer[offset:], math.Float64bits(value))
+}
+
+
+func putChars(buffer []byte, offset int, value string) {
+	copy(buffer[offset:], value)
+}
+
+
+func checkWritingData(header FileHeader, signals [][]int32) (int, error) {
+	channelsCount := int(header.ChannelsCount)
+	if channelsCount == 0 {
+		channelsCount = len(signals)
+	}
+	if !isPlausibleChannelsCount(uint16(channelsCount)) {
+		return 0, BadHeaderData{message: "Invalid channels count"}
+	}
+	if len(signals) != channelsCount {
+		return 0, BadSignalData{message: "Signals count is not equal to channels count"}
+	}
+
+	for i := 1; i < len(signals); i++ {
+		if len(signals[i]) != len(signals[0]) {
+			return 0, BadSignalData{message: "Signals have different lengths"}
+		}
+	}
+
+	if header.Frequency == 0 {
+		return 0, BadHeaderData{message: "Invalid frequency"}
+	}
+
+	if len(header.Channels) != 0 && len(header.Channels) != channelsCount {
+		return 0, BadHeaderData{message: "Channel headers count is not equal to channels count"}
+	}
+	return channelsCount, nil
+}
+
+
+func formatCoordinateSigma(value float64, degreesDigits int, positiveSymbol byte, negativeSymbol byte) string {
+	symbol := positiveSymbol
+	if value < 0 {
+		symbol = negativeSymbol
+		value = -value
+	}
+
+	degrees := int(value)
+	minutes := truncate((value - float64(degrees)) * 60, 2)
+	if minutes >= 60 {
+		degrees++
+		minutes -= 60
+	}
+	return fmt.Sprintf("%0*d%05.2f%c", degreesDigits, degrees, minutes, symbol)
+}
+
+
+func getTimeBeginBaikal7(datetimeStart time.Time) (uint64, error) {
+	diff := datetimeStart.Sub(baikal7Epoch)
+	if diff < 0 {
+		return 0, BadHeaderData{message: "Datetime start is less than 1980-01-01"}
+	}
+
+	seconds := uint64(diff / time.Second)
+	nanoseconds := uint64(diff % time.Second)
+	ticks := uint64(math.Round(float64(nanoseconds) * BAIKAL7_TIME_TICKS_PER_SECOND / 1e9))
+	return seconds * BAIKAL7_TIME_TICKS_PER_SECOND + ticks, nil
+}
+
+
+func encodeBaikal7Header(header FileHeader, channelsCount int) ([]byte, error) {
+	timeBegin, err := getTimeBeginBaikal7(header.DatetimeStart.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, headerMemorySize(channelsCount))
+	putUnsignedShort(buffer, 0, uint16(channelsCount))
+	encodeBaikalHeaderFields(buffer, header)
+	putDouble(buffer, 48, header.TimeSync.SampleInterval)
+	putDouble(buffer, 56, header.TimeSync.SecondsOfDay)
+	putLong(buffer, 104, timeBegin)
+	return buffer, nil
+}
+
+
+func encodeBaikal8Header(header FileHeader, channelsCount int) ([]byte, error) {
+	datetimeStart := header.DatetimeStart.UTC()
+	dayStart := time.Date(
+		datetimeStart.Year(), datetimeStart.Month(), datetimeStart.Day(),
+		0, 0, 0, 0, time.UTC)
+
+	buffer := make([]byte, headerMemorySize(channelsCount))
+	putUnsignedShort(buffer, 0, uint16(channelsCount))
+	putUnsignedShort(buffer, 6, uint16(datetimeStart.Day()))
+	putUnsignedShort(buffer, 8, uint16(datetimeStart.Month()))
+	putUnsignedShort(buffer, 10, uint16(datetimeStart.Year()))
+	encodeBaikalHeaderFields(buffer, header)
+	putDouble(buffer, 48, 1 / float64(header.Frequency))
+	putDouble(buffer, 56, datetimeStart.Sub(dayStart).Seconds())
+	putLong(buffer, 104, header.TimeSync.TimeBegin)
+	return buffer, nil
+}
+
+
+func encodeSigmaHeader(header FileHeader, channelsCount int) ([]byte, error) {
+	datetimeStart := header.DatetimeStart.UTC()
+	if datetimeStart.Year() < 2010 || datetimeStart.Year() > 2099 {
+		return nil, BadHeaderData{message: "Sigma format supports years from 2010 to 2099 only"}
+	}
+
+	dateNum := (datetimeStart.Year() - 2000) * 10000 + int(datetimeStart.Month()) * 100 + datetimeStart.Day()
+	timeNum := datetimeStart.Hour() * 10000 + datetimeStart.Minute() * 100 + datetimeStart.Second()
+
+	latitude := formatCoordinateSigma(header.Coordinate.Latitude, 2, 'N', 'S')
+	longitude := formatCoordinateSigma(header.Coordinate.Longitude, 3, 'E', 'W')
+	if len(latitude) != 8 || len(longitude) != 9 {
+		return nil, BadHeaderData{message: "Invalid coordinate for Sigma format"}
+	}
+
+	buffer := make([]byte, headerMemorySize(channelsCount))
+	putUnsignedShort(buffer, 12, uint16(channelsCount))
+	encodeSigmaHeaderFields(buffer, header)
+	putUnsignedShort(buffer, 24, header.Frequency)
+	putChars(buffer, 40, latitude)
+	putChars(buffer, 48, longitude)
+	putUnsignedInt(buffer, 60, uint32(dateNum))
+	putUnsignedInt(buffer, 64, uint32(timeNum))
+	return buffer, nil
+}
+
+
+func encodeHeader(formatType string, header FileHeader, channelsCount int) ([]byte, error) {
+	switch formatType {
+	case BAIKAL7_FMT:
+		return encodeBaikal7Header(header, channelsCount)
+	case BAIKAL8_FMT:
+		return encodeBaikal8Header(header, channelsCount)
+	case SIGMA_FMT:
+		return encodeSigmaHeader(header, channelsCount)
+	default:
+		return nil, BadFilePath{message: "Unknown format type"}
+	}
+}
+
+
+func writeSignals(writer io.Writer, signals [][]int32) error {
+	channelsCount := len(signals)
+	if channelsCount == 0 {
+		return nil
+	}
+
+	oneRecordBytesSize := 4 * channelsCount
+	recordsPerBlock := BASE_MEMORY_BLOCK_SIZE / oneRecordBytesSize
+	buffer := make([]byte, recordsPerBlock * oneRecordBytesSize)
+
+	signalLength := len(signals[0])
+	for blockStart := 0; blockStart < signalLength; blockStart += recordsPerBlock {
+		blockStop := blockStart + recordsPerBlock
+		if blockStop > signalLength {
+			blockStop = signalLength
+		}
+
+		position := 0
+		for i := blockStart; i < blockStop; i++ {
+			for j := 0; j < channelsCount; j++ {
+				putUnsignedInt(buffer, position, uint32(signals[j][i]))
+				position += 4
+			}
+		}
+
+		if _, err := writer.Write(buffer[:position]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+
+func WriteBinary(writer io.Writer, formatType string, header FileHeader, signals [][]int32) error {
+	channelsCount, err := checkWritingData(header, signals)
+	if err != nil {
+		return err
+	}
+
+	headerBytes, err := encodeHeader(formatType, header, channelsCount)
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(headerBytes); err != nil {
+		return err
+	}
+	return writeSignals(writer, signals)
+}
+
+
+func WriteBinaryFile(path string, formatType string, header FileHeader, signals [][]int32) error {
+	if len(path) == 0 {
+		return BadFilePath{message: "Empty file path"}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	err = WriteBinary(writer, formatType, header, signals)
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	closeErr := file.Close()
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return closeErr
+}
+
+
+func WriteBaikal7File(path string, header FileHeader, signals [][]int32) error {
+	return WriteBinaryFile(path, BAIKAL7_FMT, header, signals)
+}
+
+
+func WriteBaikal8File(path string, header FileHeader, signals [][]int32) error {
+	return WriteBinaryFile(path, BAIKAL8_FMT, header, signals)
+}
+
+
+func WriteSigmaFile(path string, header FileHeader, signals [][]int32) error {
+	return WriteBinaryFile(path, SIGMA_FMT, header, signals)
+}
@@ -0,0 +1,117 @@
+package binaryfile
+
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+
+func fullTestHeader(t *testing.T, formatType string) FileHeader {
+	header := FileHeader{
+		ChannelsCount: 3,
+		Frequency: 1000,
+		DatetimeStart: time.Date(2022, 1, 20, 8, 21, 5, 500000000, time.UTC),
+		Coordinate: Coordinate{Latitude: -55.5, Longitude: 84.25},
+		StationName: "TEST",
+		InstrumentSerial: 1234,
+		Version: 2,
+		TestType: 1,
+		Adc: AdcSettings{Bits: 24, Gain: 4, Filter: 1},
+		Gps: GpsInfo{SatellitesCount: 9, ValidFlag: 1, SyncFlag: 1, Altitude: 312.5},
+		TimeSync: TimeSyncInfo{SampleInterval: 0.001, SecondsOfDay: 30065.5, Correction: 0.25},
+		Channels: []ChannelHeader{
+			{PhysicalNumber: 1, Gain: 2, Name: "Z", Units: "counts", Sensitivity: 1.5},
+			{PhysicalNumber: 2, Gain: 2, Name: "X", Units: "counts", Sensitivity: 2.5},
+			{PhysicalNumber: 3, Gain: 2, Name: "Y", Units: "counts", Sensitivity: 3.5},
+		}}
+
+	switch formatType {
+	case BAIKAL7_FMT:
+		timeBegin, err := getTimeBeginBaikal7(header.DatetimeStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header.TimeSync.TimeBegin = timeBegin
+	case BAIKAL8_FMT:
+		header.TimeSync.TimeBegin = 987654321
+	case SIGMA_FMT:
+		header.DatetimeStart = header.DatetimeStart.Truncate(time.Second)
+		header.Gps.Altitude = 0
+		header.TimeSync = TimeSyncInfo{SampleInterval: 0.001, Correction: 0.25}
+	}
+	return header
+}
+
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	signals := testSignals(3, 5000)
+	signals[0][1], signals[1][2], signals[2][3] = math.MaxInt32, math.MinInt32, -1
+
+	readers := map[string]func(string) (FileHeader, error){
+		BAIKAL7_FMT: ReadBaikal7Header,
+		BAIKAL8_FMT: ReadBaikal8Header,
+		SIGMA_FMT: ReadSigmaHeader,
+	}
+	for formatType, readHeader := range readers {
+		header := fullTestHeader(t, formatType)
+		path := filepath.Join(t.TempDir(), "record." + BINARY_FILE_FORMATS[formatType])
+		if err := WriteBinaryFile(path, formatType, header, signals); err != nil {
+			t.Fatalf("%s: %v", formatType, err)
+		}
+
+		result, err := readHeader(path)
+		if err != nil {
+			t.Fatalf("%s: %v", formatType, err)
+		}
+		if !reflect.DeepEqual(result, header) {
+			t.Errorf("%s header\n got %+v\nwant %+v", formatType, result, header)
+		}
+
+		recording, err := OpenRecording(path)
+		if err != nil {
+			t.Fatalf("%s: %v", formatType, err)
+		}
+		defer recording.Close()
+		datetimeStop, err := recording.DatetimeStop()
+		if err != nil {
+			t.Fatalf("%s: %v", formatType, err)
+		}
+		trace, err := recording.ReadSignals(recording.DatetimeStart(), datetimeStop, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", formatType, err)
+		}
+		if !reflect.DeepEqual(trace.Signals, signals) {
+			t.Errorf("%s signals differ from written ones", formatType)
+		}
+	}
+}
+
+
+func TestZeroFrequencyHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "record.00")
+	if err := WriteBinaryFile(path, BAIKAL7_FMT, testHeader(), testSignals(3, 1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.WriteAt([]byte{0, 0}, 22)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadBaikal7Header(path); !errors.Is(err, BadHeaderData{}) {
+		t.Errorf("Zero frequency header: %v", err)
+	}
+}
@@ -0,0 +1,338 @@
+package catalog
+
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+type Entry struct {
+	FileInfo binaryfile.FileInfo
+	Size int64
+	ModTime time.Time
+	Error string
+}
+
+func (entry Entry) IsRecording() bool {
+	return len(entry.Error) == 0
+}
+
+func (entry Entry) isSameFile(size int64, modTime time.Time) bool {
+	return entry.Size == size && entry.ModTime.Equal(modTime)
+}
+
+
+type ScanResult struct {
+	FilesCount int
+	AddedCount int
+	UpdatedCount int
+	RemovedCount int
+	UnchangedCount int
+	FailedCount int
+	NotRecordingsCount int
+	Errors []ScanError
+}
+
+func (result ScanResult) IsChanged() bool {
+	return result.AddedCount + result.UpdatedCount + result.RemovedCount > 0
+}
+
+func (result ScanResult) isSkipped(path string) bool {
+	for _, scanError := range result.Errors {
+		if path == scanError.Path || strings.HasPrefix(path, scanError.Path + string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+
+type scanTask struct {
+	path string
+	size int64
+	modTime time.Time
+	isNew bool
+}
+
+
+type Catalog struct {
+	WorkersCount int
+	root string
+	indexPath string
+	entries map[string]Entry
+	walkDir func(string, fs.WalkDirFunc) error
+}
+
+
+func OpenCatalog(root string) (*Catalog, error) {
+	return OpenCatalogIndex(root, filepath.Join(root, DEFAULT_INDEX_NAME))
+}
+
+
+func OpenCatalogIndex(root string, indexPath string) (*Catalog, error) {
+	absoluteRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	absoluteIndexPath, err := filepath.Abs(indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := loadIndex(absoluteIndexPath, absoluteRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Catalog{
+		root: absoluteRoot,
+		indexPath: absoluteIndexPath,
+		entries: entries,
+		walkDir: filepath.WalkDir}, nil
+}
+
+func (catalog *Catalog) Root() string {
+	return catalog.root
+}
+
+func (catalog *Catalog) IndexPath() string {
+	return catalog.indexPath
+}
+
+func (catalog *Catalog) workersCount() int {
+	if catalog.WorkersCount > 0 {
+		return catalog.WorkersCount
+	}
+	return runtime.NumCPU()
+}
+
+func (catalog *Catalog) isIndexFile(path string) bool {
+	if path == catalog.indexPath {
+		return true
+	}
+
+	indexName := filepath.Base(catalog.indexPath)
+	return filepath.Dir(path) == filepath.Dir(catalog.indexPath) && strings.HasPrefix(filepath.Base(path), indexName + ".")
+}
+
+func (catalog *Catalog) collectTasks(ctx context.Context) ([]scanTask, map[string]bool, []ScanError, error) {
+	tasks := []scanTask{}
+	foundPaths := map[string]bool{}
+	scanErrors := []ScanError{}
+	err := catalog.walkDir(catalog.root, func(path string, dirEntry fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err != nil {
+			if path == catalog.root {
+				return err
+			}
+
+			scanErrors = append(scanErrors, ScanError{Path: path, Err: err})
+			if dirEntry != nil && dirEntry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !dirEntry.Type().IsRegular() || catalog.isIndexFile(path) {
+			return nil
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			scanErrors = append(scanErrors, ScanError{Path: path, Err: err})
+			return nil
+		}
+
+		foundPaths[path] = true
+		entry, isExists := catalog.entries[path]
+		if isExists && entry.isSameFile(info.Size(), info.ModTime()) {
+			return nil
+		}
+
+		tasks = append(tasks, scanTask{
+			path: path,
+			size: info.Size(),
+			modTime: info.ModTime(),
+			isNew: !isExists})
+		return nil
+	})
+	return tasks, foundPaths, scanErrors, err
+}
+
+
+func isRecordingFile(path string, size int64) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	_, err = binaryfile.DetectFormat(file, size, strings.TrimPrefix(filepath.Ext(path), "."))
+	if errors.Is(err, binaryfile.BadFilePath{}) || errors.Is(err, binaryfile.BadHeaderData{}) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+
+func readEntry(task scanTask) (Entry, bool) {
+	entry := Entry{
+		FileInfo: binaryfile.FileInfo{Path: task.path},
+		Size: task.size,
+		ModTime: task.modTime}
+
+	isRecording, err := isRecordingFile(task.path, task.size)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry, true
+	}
+	if !isRecording {
+		return entry, false
+	}
+
+	recording, err := binaryfile.OpenRecording(task.path)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry, true
+	}
+	defer recording.Close()
+
+	fileInfo, err := recording.FileInfo()
+	if err != nil {
+		entry.Error = err.Error()
+		return entry, true
+	}
+	entry.FileInfo = fileInfo
+	return entry, true
+}
+
+
+func readEntries(ctx context.Context, tasks []scanTask, workersCount int) ([]Entry, []bool) {
+	entries := make([]Entry, len(tasks))
+	isRecordings := make([]bool, len(tasks))
+	indexes := make(chan int)
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < workersCount; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range indexes {
+				entries[index], isRecordings[index] = readEntry(tasks[index])
+			}
+		}()
+	}
+
+	for i := range tasks {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	waitGroup.Wait()
+	return entries, isRecordings
+}
+
+func (catalog *Catalog) Scan(ctx context.Context) (ScanResult, error) {
+	tasks, foundPaths, scanErrors, err := catalog.collectTasks(ctx)
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	entries, isRecordings := readEntries(ctx, tasks, catalog.workersCount())
+	if err := ctx.Err(); err != nil {
+		return ScanResult{}, err
+	}
+
+	result := ScanResult{
+		FilesCount: len(foundPaths),
+		UnchangedCount: len(foundPaths) - len(tasks),
+		Errors: scanErrors}
+	for i, entry := range entries {
+		if !isRecordings[i] {
+			result.NotRecordingsCount++
+			if !tasks[i].isNew {
+				delete(catalog.entries, entry.FileInfo.Path)
+				result.RemovedCount++
+			}
+			continue
+		}
+
+		if tasks[i].isNew {
+			result.AddedCount++
+		} else {
+			result.UpdatedCount++
+		}
+
+		if !entry.IsRecording() {
+			result.FailedCount++
+		}
+		catalog.entries[entry.FileInfo.Path] = entry
+	}
+
+	for entryPath := range catalog.entries {
+		if !foundPaths[entryPath] && !result.isSkipped(entryPath) {
+			delete(catalog.entries, entryPath)
+			result.RemovedCount++
+		}
+	}
+
+	if result.IsChanged() {
+		if err := catalog.Save(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (catalog *Catalog) Save() error {
+	return saveIndex(catalog.indexPath, catalog.entries, catalog.root)
+}
+
+func (catalog *Catalog) Entry(path string) (Entry, bool) {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return Entry{}, false
+	}
+
+	entry, isExists := catalog.entries[absolutePath]
+	return entry, isExists
+}
+
+func (catalog *Catalog) Entries() []Entry {
+	entries := make([]Entry, 0, len(catalog.entries))
+	for _, entry := range catalog.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FileInfo.Path < entries[j].FileInfo.Path
+	})
+	return entries
+}
+
+func (catalog *Catalog) Recordings() []binaryfile.FileInfo {
+	recordings := []binaryfile.FileInfo{}
+	for _, entry := range catalog.Entries() {
+		if entry.IsRecording() {
+			recordings = append(recordings, entry.FileInfo)
+		}
+	}
+	return recordings
+}
@@ -0,0 +1,146 @@
+package catalog
+
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+func writeTestRecording(t *testing.T, path string) {
+	header := binaryfile.FileHeader{
+		Frequency: 100,
+		DatetimeStart: time.Date(2022, 1, 20, 0, 0, 0, 0, time.UTC),
+		Coordinate: binaryfile.Coordinate{Latitude: 55.5, Longitude: 84.25}}
+	signals := [][]int32{make([]int32, 1000), make([]int32, 1000), make([]int32, 1000)}
+	if err := binaryfile.WriteBinaryFile(path, binaryfile.BAIKAL7_FMT, header, signals); err != nil {
+		t.Fatal(err)
+	}
+}
+
+
+func TestScanSkipsUnreadableDirectory(t *testing.T) {
+	root := t.TempDir()
+	lockedDirectory := filepath.Join(root, "locked")
+	if err := os.Mkdir(lockedDirectory, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestRecording(t, filepath.Join(root, "first.00"))
+	writeTestRecording(t, filepath.Join(lockedDirectory, "second.00"))
+	writeTestRecording(t, filepath.Join(root, "third.00"))
+
+	recordingsCatalog, err := OpenCatalogIndex(root, filepath.Join(t.TempDir(), DEFAULT_INDEX_NAME))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recordingsCatalog.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	recordingsCatalog.walkDir = func(root string, walkFunc fs.WalkDirFunc) error {
+		return filepath.WalkDir(root, func(path string, dirEntry fs.DirEntry, err error) error {
+			if path == lockedDirectory && err == nil {
+				return walkFunc(path, dirEntry, fs.ErrPermission)
+			}
+			return walkFunc(path, dirEntry, err)
+		})
+	}
+
+	result, err := recordingsCatalog.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Path != lockedDirectory {
+		t.Errorf("Scan errors %v, want one for %s", result.Errors, lockedDirectory)
+	}
+	if result.RemovedCount != 0 {
+		t.Errorf("Entries under unreadable directory are removed: %d", result.RemovedCount)
+	}
+	if recordingsCount := len(recordingsCatalog.Recordings()); recordingsCount != 3 {
+		t.Errorf("Recordings count %d, want 3", recordingsCount)
+	}
+}
+
+
+func TestScanMissingRoot(t *testing.T) {
+	recordingsCatalog, err := OpenCatalogIndex(filepath.Join(t.TempDir(), "missing"), filepath.Join(t.TempDir(), DEFAULT_INDEX_NAME))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recordingsCatalog.Scan(context.Background()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Scan of missing root: %v", err)
+	}
+}
+
+
+func TestScanSkipsNotRecordings(t *testing.T) {
+	root := t.TempDir()
+	writeTestRecording(t, filepath.Join(root, "first.00"))
+	notRecordings := map[string][]byte{
+		"notes.txt": []byte("station notes"),
+		"noise.00": bytes.Repeat([]byte{0xff}, 4096),
+	}
+	for name, content := range notRecordings {
+		if err := os.WriteFile(filepath.Join(root, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recordingsCatalog, err := OpenCatalogIndex(root, filepath.Join(t.TempDir(), DEFAULT_INDEX_NAME))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := recordingsCatalog.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AddedCount != 1 || result.FailedCount != 0 || result.NotRecordingsCount != 2 {
+		t.Errorf("Scan result %+v, want 1 added and 2 not recordings", result)
+	}
+	if entries := recordingsCatalog.Entries(); len(entries) != 1 {
+		t.Errorf("Catalog has %d entries, want 1", len(entries))
+	}
+
+	recordingPath := filepath.Join(root, "first.00")
+	if err := os.WriteFile(recordingPath, []byte("replaced"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = recordingsCatalog.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, isExists := recordingsCatalog.Entry(recordingPath); isExists || result.RemovedCount != 1 {
+		t.Errorf("Replaced recording is kept in catalog: %+v", result)
+	}
+}
+
+
+func TestQueryDirectoryIndexPath(t *testing.T) {
+	root := t.TempDir()
+	writeTestRecording(t, filepath.Join(root, "first.00"))
+	indexPath := filepath.Join(t.TempDir(), DEFAULT_INDEX_NAME)
+
+	center := binaryfile.Coordinate{Latitude: 55.5, Longitude: 84.26}
+	recordings, err := QueryDirectory(context.Background(), root, indexPath, Query{Radius: &Radius{Center: center, Distance: 1000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordings) != 1 {
+		t.Errorf("Query found %d recordings within 1000 m, want 1", len(recordings))
+	}
+
+	if _, err := os.Stat(indexPath); err != nil {
+		t.Errorf("Index is not written to given path: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, DEFAULT_INDEX_NAME)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Index is written to data root: %v", err)
+	}
+}
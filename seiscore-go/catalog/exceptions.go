@@ -0,0 +1,28 @@
+package catalog
+
+import (
+	"fmt"
+)
+
+
+type InvalidIndex struct {
+	message string
+}
+
+func (customError InvalidIndex) Error() string {
+	return fmt.Sprintf("InvalidIndex: %s", customError.message)
+}
+
+
+type ScanError struct {
+	Path string
+	Err error
+}
+
+func (customError ScanError) Error() string {
+	return fmt.Sprintf("%s: %v", customError.Path, customError.Err)
+}
+
+func (customError ScanError) Unwrap() error {
+	return customError.Err
+}
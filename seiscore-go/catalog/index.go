@@ -0,0 +1,204 @@
+package catalog
+
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+const (
+	DEFAULT_INDEX_NAME = ".seiscore-index.jsonl"
+	MAX_INDEX_LINE_SIZE = 1024 * 1024
+)
+
+
+type entryJSON struct {
+	Path string `json:"path"`
+	Size int64 `json:"size"`
+	ModTime string `json:"mod_time"`
+	FormatType string `json:"format_type,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+	Frequency uint16 `json:"frequency,omitempty"`
+	DatetimeStart string `json:"datetime_start,omitempty"`
+	DatetimeStop string `json:"datetime_stop,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	Latitude float64 `json:"latitude,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+
+func formatDatetime(datetime time.Time) string {
+	if datetime.IsZero() {
+		return ""
+	}
+	return datetime.UTC().Format(time.RFC3339Nano)
+}
+
+
+func parseDatetime(value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+
+func newEntryJSON(entry Entry, relativePath string) entryJSON {
+	return entryJSON{
+		Path: filepath.ToSlash(relativePath),
+		Size: entry.Size,
+		ModTime: formatDatetime(entry.ModTime),
+		FormatType: entry.FileInfo.FormatType,
+		Channels: entry.FileInfo.Channels,
+		Frequency: entry.FileInfo.Frequency,
+		DatetimeStart: formatDatetime(entry.FileInfo.TimeStart),
+		DatetimeStop: formatDatetime(entry.FileInfo.TimeStop),
+		Longitude: entry.FileInfo.Coordinate.Longitude,
+		Latitude: entry.FileInfo.Coordinate.Latitude,
+		Error: entry.Error}
+}
+
+
+func (item entryJSON) entry(root string) (Entry, error) {
+	modTime, err := parseDatetime(item.ModTime)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	timeStart, err := parseDatetime(item.DatetimeStart)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	timeStop, err := parseDatetime(item.DatetimeStop)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{
+		FileInfo: binaryfile.FileInfo{
+			Path: filepath.Join(root, filepath.FromSlash(item.Path)),
+			FormatType: item.FormatType,
+			Channels: item.Channels,
+			Frequency: item.Frequency,
+			TimeStart: timeStart,
+			TimeStop: timeStop,
+			Coordinate: binaryfile.Coordinate{
+				Longitude: item.Longitude,
+				Latitude: item.Latitude}},
+		Size: item.Size,
+		ModTime: modTime,
+		Error: item.Error}, nil
+}
+
+
+func readIndex(reader io.Reader, root string) (map[string]Entry, error) {
+	entries := map[string]Entry{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64 * 1024), MAX_INDEX_LINE_SIZE)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var item entryJSON
+		if err := json.Unmarshal(line, &item); err != nil {
+			return nil, InvalidIndex{message: fmt.Sprintf("line %d: %s", lineNumber, err)}
+		}
+
+		if len(item.Path) == 0 {
+			return nil, InvalidIndex{message: fmt.Sprintf("line %d: empty path", lineNumber)}
+		}
+
+		entry, err := item.entry(root)
+		if err != nil {
+			return nil, InvalidIndex{message: fmt.Sprintf("line %d: %s", lineNumber, err)}
+		}
+		entries[entry.FileInfo.Path] = entry
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+
+func loadIndex(indexPath string, root string) (map[string]Entry, error) {
+	file, err := os.Open(indexPath)
+	if os.IsNotExist(err) {
+		return map[string]Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries, err := readIndex(file, root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", indexPath, err)
+	}
+	return entries, nil
+}
+
+
+func writeIndex(writer io.Writer, entries map[string]Entry, root string) error {
+	paths := make([]string, 0, len(entries))
+	for entryPath := range entries {
+		paths = append(paths, entryPath)
+	}
+	sort.Strings(paths)
+
+	bufferedWriter := bufio.NewWriter(writer)
+	encoder := json.NewEncoder(bufferedWriter)
+	for _, entryPath := range paths {
+		relativePath, err := filepath.Rel(root, entryPath)
+		if err != nil {
+			return err
+		}
+
+		if err := encoder.Encode(newEntryJSON(entries[entryPath], relativePath)); err != nil {
+			return err
+		}
+	}
+	return bufferedWriter.Flush()
+}
+
+
+func saveIndex(indexPath string, entries map[string]Entry, root string) error {
+	file, err := os.CreateTemp(filepath.Dir(indexPath), filepath.Base(indexPath) + ".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+
+	if err := writeIndex(file, entries, root); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := os.Rename(tempPath, indexPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
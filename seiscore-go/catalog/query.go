@@ -0,0 +1,204 @@
+package catalog
+
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+const (
+	EARTH_RADIUS = 6371008.8
+)
+
+
+type BoundingBox struct {
+	MinLongitude float64
+	MinLatitude float64
+	MaxLongitude float64
+	MaxLatitude float64
+}
+
+func (box BoundingBox) Contains(coordinate binaryfile.Coordinate) bool {
+	if coordinate.Latitude < box.MinLatitude || coordinate.Latitude > box.MaxLatitude {
+		return false
+	}
+
+	if box.MinLongitude <= box.MaxLongitude {
+		return coordinate.Longitude >= box.MinLongitude && coordinate.Longitude <= box.MaxLongitude
+	}
+	return coordinate.Longitude >= box.MinLongitude || coordinate.Longitude <= box.MaxLongitude
+}
+
+
+type Radius struct {
+	Center binaryfile.Coordinate
+	// Great-circle distance in metres, as returned by Distance.
+	Distance float64
+}
+
+func (radius Radius) Contains(coordinate binaryfile.Coordinate) bool {
+	return Distance(radius.Center, coordinate) <= radius.Distance
+}
+
+
+func Distance(first binaryfile.Coordinate, second binaryfile.Coordinate) float64 {
+	firstLatitude := first.Latitude * math.Pi / 180
+	secondLatitude := second.Latitude * math.Pi / 180
+	latitudeDiff := secondLatitude - firstLatitude
+	longitudeDiff := (second.Longitude - first.Longitude) * math.Pi / 180
+
+	value := math.Pow(math.Sin(latitudeDiff / 2), 2) +
+		math.Cos(firstLatitude) * math.Cos(secondLatitude) * math.Pow(math.Sin(longitudeDiff / 2), 2)
+	return 2 * EARTH_RADIUS * math.Asin(math.Min(1, math.Sqrt(value)))
+}
+
+
+type Query struct {
+	TimeStart time.Time
+	TimeStop time.Time
+	FormatTypes []string
+	Frequencies []uint16
+	BoundingBox *BoundingBox
+	Radius *Radius
+}
+
+func (query Query) isTimeOverlapped(fileInfo binaryfile.FileInfo) bool {
+	if !query.TimeStart.IsZero() && !fileInfo.TimeStop.After(query.TimeStart) {
+		return false
+	}
+	if !query.TimeStop.IsZero() && !fileInfo.TimeStart.Before(query.TimeStop) {
+		return false
+	}
+	return true
+}
+
+func (query Query) isFormatMatched(formatType string) bool {
+	if len(query.FormatTypes) == 0 {
+		return true
+	}
+
+	for _, queryFormatType := range query.FormatTypes {
+		if queryFormatType == formatType {
+			return true
+		}
+	}
+	return false
+}
+
+func (query Query) isFrequencyMatched(frequency uint16) bool {
+	if len(query.Frequencies) == 0 {
+		return true
+	}
+
+	for _, queryFrequency := range query.Frequencies {
+		if queryFrequency == frequency {
+			return true
+		}
+	}
+	return false
+}
+
+func (query Query) Match(fileInfo binaryfile.FileInfo) bool {
+	if !query.isTimeOverlapped(fileInfo) {
+		return false
+	}
+
+	if !query.isFormatMatched(fileInfo.FormatType) || !query.isFrequencyMatched(fileInfo.Frequency) {
+		return false
+	}
+
+	if query.BoundingBox != nil && !query.BoundingBox.Contains(fileInfo.Coordinate) {
+		return false
+	}
+
+	if query.Radius != nil && !query.Radius.Contains(fileInfo.Coordinate) {
+		return false
+	}
+	return true
+}
+
+func (query Query) window(fileInfo binaryfile.FileInfo) (time.Time, time.Time) {
+	timeStart, timeStop := fileInfo.TimeStart, fileInfo.TimeStop
+	if query.TimeStart.After(timeStart) {
+		timeStart = query.TimeStart
+	}
+	if !query.TimeStop.IsZero() && query.TimeStop.Before(timeStop) {
+		timeStop = query.TimeStop
+	}
+	return timeStart, timeStop
+}
+
+
+func sortByTime(recordings []binaryfile.FileInfo) {
+	sort.SliceStable(recordings, func(i, j int) bool {
+		if recordings[i].TimeStart.Equal(recordings[j].TimeStart) {
+			return recordings[i].Path < recordings[j].Path
+		}
+		return recordings[i].TimeStart.Before(recordings[j].TimeStart)
+	})
+}
+
+
+func (catalog *Catalog) Query(query Query) []binaryfile.FileInfo {
+	recordings := []binaryfile.FileInfo{}
+	for _, entry := range catalog.entries {
+		if entry.IsRecording() && query.Match(entry.FileInfo) {
+			recordings = append(recordings, entry.FileInfo)
+		}
+	}
+	sortByTime(recordings)
+	return recordings
+}
+
+
+type Window struct {
+	FileInfo binaryfile.FileInfo
+	Trace binaryfile.MultichannelTrace
+}
+
+
+func readWindow(fileInfo binaryfile.FileInfo, timeStart time.Time, timeStop time.Time, components []string) (binaryfile.MultichannelTrace, error) {
+	recording, err := binaryfile.OpenRecording(fileInfo.Path)
+	if err != nil {
+		return binaryfile.MultichannelTrace{}, err
+	}
+	defer recording.Close()
+
+	return recording.ReadSignals(timeStart, timeStop, components)
+}
+
+
+func (catalog *Catalog) ReadWindows(query Query, components []string) ([]Window, error) {
+	windows := []Window{}
+	for _, fileInfo := range catalog.Query(query) {
+		timeStart, timeStop := query.window(fileInfo)
+		if !timeStop.After(timeStart) {
+			continue
+		}
+
+		trace, err := readWindow(fileInfo, timeStart, timeStop, components)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, Window{FileInfo: fileInfo, Trace: trace})
+	}
+	return windows, nil
+}
+
+
+func QueryDirectory(ctx context.Context, root string, indexPath string, query Query) ([]binaryfile.FileInfo, error) {
+	catalog, err := OpenCatalogIndex(root, indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := catalog.Scan(ctx); err != nil {
+		return nil, err
+	}
+	return catalog.Query(query), nil
+}
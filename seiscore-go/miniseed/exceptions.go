@@ -0,0 +1,23 @@
+package miniseed
+
+import (
+	"fmt"
+)
+
+
+type InvalidParameter struct {
+	message string
+}
+
+func (customError InvalidParameter) Error() string {
+	return fmt.Sprintf("InvalidParameter: %s", customError.message)
+}
+
+
+type BadRecordData struct {
+	message string
+}
+
+func (customError BadRecordData) Error() string {
+	return fmt.Sprintf("BadRecordData: %s", customError.message)
+}
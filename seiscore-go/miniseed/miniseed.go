@@ -0,0 +1,138 @@
+package miniseed
+
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+
+const (
+	INT32_ENCODING, STEIM1_ENCODING, STEIM2_ENCODING = 3, 10, 11
+	BIG_ENDIAN_WORD_ORDER, LITTLE_ENDIAN_WORD_ORDER = 1, 0
+	FIXED_HEADER_SIZE = 48
+	DATA_OFFSET = 64
+	MIN_RECORD_LENGTH, MAX_RECORD_LENGTH = 128, 65536
+	DEFAULT_RECORD_LENGTH = 4096
+	DEFAULT_NETWORK_CODE = "XX"
+	DEFAULT_INSTRUMENT_CODE = 'H'
+	BLOCKETTE_1000, BLOCKETTE_1001 = 1000, 1001
+)
+
+
+var DEFAULT_ORIENTATION_CODES = map[string]byte{
+	"Z": 'Z',
+	"X": 'N',
+	"Y": 'E',
+}
+
+
+type Trace struct {
+	Network string
+	Station string
+	Location string
+	Channel string
+	StartTime time.Time
+	SampleRate float64
+	Samples []int32
+}
+
+func (trace Trace) Id() string {
+	return strings.Join([]string{trace.Network, trace.Station, trace.Location, trace.Channel}, ".")
+}
+
+func (trace Trace) EndTime() time.Time {
+	if len(trace.Samples) == 0 {
+		return trace.StartTime
+	}
+	duration := float64(len(trace.Samples) - 1) / trace.SampleRate * 1e9
+	return trace.StartTime.Add(time.Duration(math.Round(duration)))
+}
+
+
+func BandCode(sampleRate float64) byte {
+	switch {
+	case sampleRate >= 1000:
+		return 'G'
+	case sampleRate >= 250:
+		return 'D'
+	case sampleRate >= 80:
+		return 'E'
+	case sampleRate >= 10:
+		return 'S'
+	case sampleRate > 1:
+		return 'M'
+	case sampleRate > 0.5:
+		return 'L'
+	default:
+		return 'V'
+	}
+}
+
+
+func ChannelCode(component string, channelIndex int, sampleRate float64) string {
+	orientation, isFound := DEFAULT_ORIENTATION_CODES[strings.ToUpper(component)]
+	if !isFound {
+		orientation = strconv.Itoa((channelIndex + 1) % 10)[0]
+	}
+	return string([]byte{BandCode(sampleRate), DEFAULT_INSTRUMENT_CODE, orientation})
+}
+
+
+func sampleRateFactors(sampleRate float64) (int16, int16, error) {
+	if sampleRate <= 0 || math.IsNaN(sampleRate) || math.IsInf(sampleRate, 0) {
+		return 0, 0, InvalidParameter{message: "Invalid sample rate"}
+	}
+
+	if sampleRate >= 1 {
+		if sampleRate == math.Trunc(sampleRate) && sampleRate <= math.MaxInt16 {
+			return int16(sampleRate), 1, nil
+		}
+		for multiplier := 10.0; multiplier <= 10000; multiplier *= 10 {
+			factor := sampleRate * multiplier
+			if factor == math.Trunc(factor) && factor <= math.MaxInt16 {
+				return int16(factor), int16(-multiplier), nil
+			}
+		}
+		return 0, 0, InvalidParameter{message: "Sample rate can not be represented in header"}
+	}
+
+	period := 1 / sampleRate
+	if math.Abs(period - math.Round(period)) < 1e-9 && period <= math.MaxInt16 {
+		return int16(-math.Round(period)), 1, nil
+	}
+	return 0, 0, InvalidParameter{message: "Sample rate can not be represented in header"}
+}
+
+
+func sampleRateFromFactors(factor int16, multiplier int16) float64 {
+	switch {
+	case factor == 0 || multiplier == 0:
+		return 0
+	case factor > 0 && multiplier > 0:
+		return float64(factor) * float64(multiplier)
+	case factor > 0 && multiplier < 0:
+		return -float64(factor) / float64(multiplier)
+	case factor < 0 && multiplier > 0:
+		return -float64(multiplier) / float64(factor)
+	default:
+		return 1 / (float64(factor) * float64(multiplier))
+	}
+}
+
+
+func isPowerOfTwo(value int) bool {
+	return value > 0 && value & (value - 1) == 0
+}
+
+
+func recordLengthExponent(recordLength int) uint8 {
+	exponent := uint8(0)
+	for recordLength > 1 {
+		recordLength >>= 1
+		exponent++
+	}
+	return exponent
+}
@@ -0,0 +1,241 @@
+package miniseed
+
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+
+type Record struct {
+	SequenceNumber string
+	Network string
+	Station string
+	Location string
+	Channel string
+	StartTime time.Time
+	SampleRate float64
+	Encoding uint8
+	RecordLength int
+	Samples []int32
+}
+
+func (record Record) Id() string {
+	return strings.Join([]string{record.Network, record.Station, record.Location, record.Channel}, ".")
+}
+
+
+func headerByteOrder(header []byte) binary.ByteOrder {
+	year := binary.BigEndian.Uint16(header[20:])
+	dayOfYear := binary.BigEndian.Uint16(header[22:])
+	if year >= 1900 && year <= 2500 && dayOfYear >= 1 && dayOfYear <= 366 {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+
+func readBtime(buffer []byte, order binary.ByteOrder) time.Time {
+	year := int(order.Uint16(buffer[0:]))
+	dayOfYear := int(order.Uint16(buffer[2:]))
+	fract := int(order.Uint16(buffer[8:]))
+	return time.Date(
+		year, time.January, dayOfYear,
+		int(buffer[4]), int(buffer[5]), int(buffer[6]), fract * 100000, time.UTC)
+}
+
+
+func trimCode(buffer []byte) string {
+	return strings.TrimSpace(string(buffer))
+}
+
+
+func decodeRecord(record []byte, header []byte, order binary.ByteOrder) (Record, error) {
+	samplesCount := int(order.Uint16(header[30:]))
+	factor := int16(order.Uint16(header[32:]))
+	multiplier := int16(order.Uint16(header[34:]))
+	dataOffset := int(order.Uint16(header[44:]))
+
+	result := Record{
+		SequenceNumber: string(header[0:6]),
+		Station: trimCode(header[8:13]),
+		Location: trimCode(header[13:15]),
+		Channel: trimCode(header[15:18]),
+		Network: trimCode(header[18:20]),
+		StartTime: readBtime(header[20:30], order),
+		SampleRate: sampleRateFromFactors(factor, multiplier),
+		RecordLength: len(record)}
+
+	timeCorrection := int32(order.Uint32(header[40:]))
+	if header[36] & 0x02 == 0 && timeCorrection != 0 {
+		result.StartTime = result.StartTime.Add(time.Duration(timeCorrection) * 100 * time.Microsecond)
+	}
+
+	dataOrder := binary.ByteOrder(binary.BigEndian)
+	blocketteOffset := int(order.Uint16(header[46:]))
+	isFoundBlockette1000 := false
+	for blocketteOffset != 0 {
+		if blocketteOffset + 8 > len(record) {
+			return Record{}, BadRecordData{message: "Blockette offset is out of record"}
+		}
+
+		blocketteType := order.Uint16(record[blocketteOffset:])
+		switch blocketteType {
+		case BLOCKETTE_1000:
+			result.Encoding = record[blocketteOffset + 4]
+			if record[blocketteOffset + 5] == LITTLE_ENDIAN_WORD_ORDER {
+				dataOrder = binary.LittleEndian
+			}
+			isFoundBlockette1000 = true
+		case BLOCKETTE_1001:
+			microseconds := int8(record[blocketteOffset + 5])
+			result.StartTime = result.StartTime.Add(time.Duration(microseconds) * time.Microsecond)
+		}
+
+		nextOffset := int(order.Uint16(record[blocketteOffset + 2:]))
+		if nextOffset != 0 && nextOffset <= blocketteOffset {
+			return Record{}, BadRecordData{message: "Invalid blockette chain"}
+		}
+		blocketteOffset = nextOffset
+	}
+
+	if !isFoundBlockette1000 {
+		return Record{}, BadRecordData{message: "Record has no blockette 1000"}
+	}
+
+	if dataOffset > len(record) || (samplesCount > 0 && dataOffset < FIXED_HEADER_SIZE) {
+		return Record{}, BadRecordData{message: "Invalid beginning of data"}
+	}
+	data := record[dataOffset:]
+
+	switch result.Encoding {
+	case INT32_ENCODING:
+		if samplesCount * 4 > len(data) {
+			return Record{}, BadRecordData{message: "Record contains less samples than header"}
+		}
+		result.Samples = make([]int32, samplesCount)
+		for i := range result.Samples {
+			result.Samples[i] = int32(dataOrder.Uint32(data[4 * i:]))
+		}
+	case STEIM1_ENCODING, STEIM2_ENCODING:
+		samples, err := decodeSteim(data, samplesCount, result.Encoding, dataOrder)
+		if err != nil {
+			return Record{}, err
+		}
+		result.Samples = samples
+	default:
+		return Record{}, BadRecordData{message: "Unsupported data encoding"}
+	}
+	return result, nil
+}
+
+
+func readRecord(reader io.Reader) (Record, error) {
+	start := make([]byte, MIN_RECORD_LENGTH)
+	if _, err := io.ReadFull(reader, start); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return Record{}, BadRecordData{message: "Truncated record"}
+		}
+		return Record{}, err
+	}
+
+	order := headerByteOrder(start)
+	recordLength := 0
+	blocketteOffset := int(order.Uint16(start[46:]))
+	for blocketteOffset != 0 && blocketteOffset + 8 <= len(start) {
+		if order.Uint16(start[blocketteOffset:]) == BLOCKETTE_1000 {
+			recordLength = 1 << start[blocketteOffset + 6]
+			break
+		}
+		nextOffset := int(order.Uint16(start[blocketteOffset + 2:]))
+		if nextOffset <= blocketteOffset {
+			break
+		}
+		blocketteOffset = nextOffset
+	}
+
+	if recordLength < MIN_RECORD_LENGTH || recordLength > MAX_RECORD_LENGTH {
+		return Record{}, BadRecordData{message: "Record length is unknown or invalid"}
+	}
+
+	record := make([]byte, recordLength)
+	copy(record, start)
+	if _, err := io.ReadFull(reader, record[MIN_RECORD_LENGTH:]); err != nil {
+		return Record{}, BadRecordData{message: "Truncated record"}
+	}
+	return decodeRecord(record, record[:FIXED_HEADER_SIZE], order)
+}
+
+
+func ReadRecords(reader io.Reader) ([]Record, error) {
+	records := []Record{}
+	for {
+		record, err := readRecord(reader)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+
+func isContinuation(trace Trace, record Record) bool {
+	if trace.Id() != record.Id() || trace.SampleRate != record.SampleRate {
+		return false
+	}
+
+	expected := trace.EndTime().Add(time.Duration(1e9 / trace.SampleRate))
+	tolerance := 0.5 / trace.SampleRate
+	return math.Abs(record.StartTime.Sub(expected).Seconds()) <= tolerance
+}
+
+
+func MergeRecords(records []Record) []Trace {
+	traces := []Trace{}
+	lastTraceIndexes := map[string]int{}
+	for _, record := range records {
+		index, isFound := lastTraceIndexes[record.Id()]
+		if isFound && isContinuation(traces[index], record) {
+			traces[index].Samples = append(traces[index].Samples, record.Samples...)
+			continue
+		}
+
+		traces = append(traces, Trace{
+			Network: record.Network,
+			Station: record.Station,
+			Location: record.Location,
+			Channel: record.Channel,
+			StartTime: record.StartTime,
+			SampleRate: record.SampleRate,
+			Samples: append([]int32{}, record.Samples...)})
+		lastTraceIndexes[record.Id()] = len(traces) - 1
+	}
+	return traces
+}
+
+
+func ReadTraces(reader io.Reader) ([]Trace, error) {
+	records, err := ReadRecords(reader)
+	if err != nil {
+		return nil, err
+	}
+	return MergeRecords(records), nil
+}
+
+
+func ReadFile(path string) ([]Trace, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadTraces(bufio.NewReader(file))
+}
@@ -0,0 +1,31 @@
+package miniseed
+
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+
+func TestBlocketteAtRecordEnd(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	writer, err := NewWriter(buffer, WriterOptions{Encoding: STEIM2_ENCODING, RecordLength: MIN_RECORD_LENGTH})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteTrace(testTrace([]int32{1, 2, 3})); err != nil {
+		t.Fatal(err)
+	}
+
+	record := buffer.Bytes()[:MIN_RECORD_LENGTH]
+	blocketteOffset := binary.BigEndian.Uint16(record[46:])
+	binary.BigEndian.PutUint16(record[blocketteOffset + 2:], MIN_RECORD_LENGTH - 4)
+	binary.BigEndian.PutUint16(record[MIN_RECORD_LENGTH - 4:], BLOCKETTE_1001)
+	binary.BigEndian.PutUint16(record[MIN_RECORD_LENGTH - 2:], 0)
+
+	if _, err := ReadRecords(bytes.NewReader(record)); !errors.As(err, &BadRecordData{}) {
+		t.Errorf("Blockette 1001 in the last 4 bytes: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package miniseed
+
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+
+const (
+	STEIM_FRAME_SIZE = 64
+	STEIM_FRAME_WORDS = 16
+)
+
+
+type steimPacking struct {
+	count int
+	bits uint
+	nibble uint32
+	dnib uint32
+}
+
+
+var steim1Packings = []steimPacking{
+	{count: 4, bits: 8, nibble: 1},
+	{count: 2, bits: 16, nibble: 2},
+	{count: 1, bits: 32, nibble: 3},
+}
+
+
+var steim2Packings = []steimPacking{
+	{count: 7, bits: 4, nibble: 3, dnib: 2},
+	{count: 6, bits: 5, nibble: 3, dnib: 1},
+	{count: 5, bits: 6, nibble: 3, dnib: 0},
+	{count: 4, bits: 8, nibble: 1},
+	{count: 3, bits: 10, nibble: 2, dnib: 3},
+	{count: 2, bits: 15, nibble: 2, dnib: 2},
+	{count: 1, bits: 30, nibble: 2, dnib: 1},
+}
+
+
+func steimPackings(encoding uint8) []steimPacking {
+	if encoding == STEIM1_ENCODING {
+		return steim1Packings
+	}
+	return steim2Packings
+}
+
+
+func isFitBits(value int64, bits uint) bool {
+	limit := int64(1) << (bits - 1)
+	return value >= -limit && value < limit
+}
+
+
+func signExtend(value uint32, bits uint) int32 {
+	return int32(value << (32 - bits)) >> (32 - bits)
+}
+
+
+func packWord(differences []int64, packings []steimPacking) (uint32, uint32, int, error) {
+	for _, packing := range packings {
+		if len(differences) < packing.count {
+			continue
+		}
+
+		isFit := true
+		for i := 0; i < packing.count; i++ {
+			if !isFitBits(differences[i], packing.bits) {
+				isFit = false
+				break
+			}
+		}
+		if !isFit {
+			continue
+		}
+
+		word := packing.dnib << 30
+		mask := uint32(math.MaxUint32 >> (32 - packing.bits))
+		for i := 0; i < packing.count; i++ {
+			shift := packing.bits * uint(packing.count - 1 - i)
+			word |= (uint32(differences[i]) & mask) << shift
+		}
+		return packing.nibble, word, packing.count, nil
+	}
+	return 0, 0, 0, BadRecordData{message: "Sample difference is too large for Steim encoding"}
+}
+
+
+func encodeSteim(samples []int32, framesCount int, encoding uint8, order binary.ByteOrder) ([]byte, int, error) {
+	if len(samples) == 0 {
+		return []byte{}, 0, nil
+	}
+
+	differences := make([]int64, len(samples))
+	for i := 1; i < len(samples); i++ {
+		differences[i] = int64(samples[i]) - int64(samples[i - 1])
+	}
+
+	packings := steimPackings(encoding)
+	words := make([]uint32, framesCount * STEIM_FRAME_WORDS)
+	differenceIndex := 0
+	for frame := 0; frame < framesCount && differenceIndex < len(differences); frame++ {
+		base := frame * STEIM_FRAME_WORDS
+		startWord := 1
+		if frame == 0 {
+			words[base + 1] = uint32(samples[0])
+			startWord = 3
+		}
+
+		control := uint32(0)
+		for word := startWord; word < STEIM_FRAME_WORDS && differenceIndex < len(differences); word++ {
+			nibble, value, count, err := packWord(differences[differenceIndex:], packings)
+			if err != nil {
+				return nil, 0, err
+			}
+			words[base + word] = value
+			control |= nibble << uint(30 - 2 * word)
+			differenceIndex += count
+		}
+		words[base] = control
+	}
+	words[2] = uint32(samples[differenceIndex - 1])
+
+	data := make([]byte, len(words) * 4)
+	for i, word := range words {
+		order.PutUint32(data[4 * i:], word)
+	}
+	return data, differenceIndex, nil
+}
+
+
+func unpackWord(nibble uint32, word uint32, encoding uint8) ([]int32, error) {
+	var packing steimPacking
+	switch {
+	case nibble == 1:
+		packing = steimPacking{count: 4, bits: 8}
+	case encoding == STEIM1_ENCODING && nibble == 2:
+		packing = steimPacking{count: 2, bits: 16}
+	case encoding == STEIM1_ENCODING && nibble == 3:
+		packing = steimPacking{count: 1, bits: 32}
+	default:
+		dnib := word >> 30
+		isFound := false
+		for _, item := range steim2Packings {
+			if item.nibble == nibble && item.dnib == dnib && item.count != 4 {
+				packing, isFound = item, true
+				break
+			}
+		}
+		if !isFound {
+			return nil, BadRecordData{message: "Invalid Steim-2 difference nibble"}
+		}
+	}
+
+	values := make([]int32, packing.count)
+	mask := uint32(math.MaxUint32 >> (32 - packing.bits))
+	for i := 0; i < packing.count; i++ {
+		shift := packing.bits * uint(packing.count - 1 - i)
+		values[i] = signExtend((word >> shift) & mask, packing.bits)
+	}
+	return values, nil
+}
+
+
+func decodeSteim(data []byte, samplesCount int, encoding uint8, order binary.ByteOrder) ([]int32, error) {
+	if samplesCount == 0 {
+		return []int32{}, nil
+	}
+
+	framesCount := len(data) / STEIM_FRAME_SIZE
+	if framesCount == 0 {
+		return nil, BadRecordData{message: "Record has no Steim frames"}
+	}
+
+	var firstSample, lastSample int32
+	differences := make([]int32, 0, samplesCount)
+	for frame := 0; frame < framesCount && len(differences) < samplesCount; frame++ {
+		base := frame * STEIM_FRAME_SIZE
+		control := order.Uint32(data[base:])
+		for word := 1; word < STEIM_FRAME_WORDS && len(differences) < samplesCount; word++ {
+			value := order.Uint32(data[base + 4 * word:])
+			if frame == 0 && word == 1 {
+				firstSample = int32(value)
+				continue
+			}
+			if frame == 0 && word == 2 {
+				lastSample = int32(value)
+				continue
+			}
+
+			nibble := (control >> uint(30 - 2 * word)) & 3
+			if nibble == 0 {
+				continue
+			}
+
+			values, err := unpackWord(nibble, value, encoding)
+			if err != nil {
+				return nil, err
+			}
+			differences = append(differences, values...)
+		}
+	}
+
+	if len(differences) < samplesCount {
+		return nil, BadRecordData{message: "Steim frames contain less samples than header"}
+	}
+
+	samples := make([]int32, samplesCount)
+	samples[0] = firstSample
+	for i := 1; i < samplesCount; i++ {
+		samples[i] = samples[i - 1] + differences[i]
+	}
+
+	if samples[samplesCount - 1] != lastSample {
+		return nil, BadRecordData{message: "Steim reverse integration constant mismatch"}
+	}
+	return samples, nil
+}
@@ -0,0 +1,325 @@
+package miniseed
+
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+type WriterOptions struct {
+	Network string
+	Station string
+	Location string
+	Encoding uint8
+	RecordLength int
+	ChannelCodes map[string]string
+}
+
+func (options WriterOptions) withDefaults() (WriterOptions, error) {
+	if len(options.Network) == 0 {
+		options.Network = DEFAULT_NETWORK_CODE
+	}
+	if options.Encoding == 0 {
+		options.Encoding = STEIM2_ENCODING
+	}
+	if options.RecordLength == 0 {
+		options.RecordLength = DEFAULT_RECORD_LENGTH
+	}
+
+	switch options.Encoding {
+	case INT32_ENCODING, STEIM1_ENCODING, STEIM2_ENCODING:
+	default:
+		return options, InvalidParameter{message: fmt.Sprintf("Unsupported encoding %d", options.Encoding)}
+	}
+
+	recordLength := options.RecordLength
+	if !isPowerOfTwo(recordLength) || recordLength < MIN_RECORD_LENGTH || recordLength > MAX_RECORD_LENGTH {
+		return options, InvalidParameter{message: fmt.Sprintf("Invalid record length %d", recordLength)}
+	}
+	return options, nil
+}
+
+func (options WriterOptions) channelCode(component string, channelIndex int, sampleRate float64) string {
+	if code, isFound := options.ChannelCodes[component]; isFound {
+		return code
+	}
+	return ChannelCode(component, channelIndex, sampleRate)
+}
+
+
+type Writer struct {
+	writer io.Writer
+	options WriterOptions
+	sequenceNumber int
+}
+
+
+func NewWriter(writer io.Writer, options WriterOptions) (*Writer, error) {
+	options, err := options.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{writer: writer, options: options}, nil
+}
+
+
+func putCode(buffer []byte, value string) {
+	for i := range buffer {
+		buffer[i] = ' '
+	}
+	copy(buffer, strings.ToUpper(value))
+}
+
+
+func putBtime(buffer []byte, datetime time.Time) {
+	datetime = datetime.UTC()
+	binary.BigEndian.PutUint16(buffer[0:], uint16(datetime.Year()))
+	binary.BigEndian.PutUint16(buffer[2:], uint16(datetime.YearDay()))
+	buffer[4] = uint8(datetime.Hour())
+	buffer[5] = uint8(datetime.Minute())
+	buffer[6] = uint8(datetime.Second())
+	buffer[7] = 0
+	binary.BigEndian.PutUint16(buffer[8:], uint16(datetime.Nanosecond() / 100000))
+}
+
+
+func (writer *Writer) maxRecordSamplesCount() int {
+	dataSize := writer.options.RecordLength - DATA_OFFSET
+	samplesCount := dataSize / 4
+	switch writer.options.Encoding {
+	case STEIM1_ENCODING:
+		samplesCount = dataSize / STEIM_FRAME_SIZE * (STEIM_FRAME_WORDS - 1) * 4
+	case STEIM2_ENCODING:
+		samplesCount = dataSize / STEIM_FRAME_SIZE * (STEIM_FRAME_WORDS - 1) * 7
+	}
+
+	if samplesCount > math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return samplesCount
+}
+
+
+func (writer *Writer) encodeData(samples []int32, data []byte) (int, error) {
+	switch writer.options.Encoding {
+	case INT32_ENCODING:
+		count := len(data) / 4
+		if count > len(samples) {
+			count = len(samples)
+		}
+		for i := 0; i < count; i++ {
+			binary.BigEndian.PutUint32(data[4 * i:], uint32(samples[i]))
+		}
+		return count, nil
+	default:
+		if maxSamplesCount := writer.maxRecordSamplesCount(); len(samples) > maxSamplesCount {
+			samples = samples[:maxSamplesCount]
+		}
+		encoded, count, err := encodeSteim(
+			samples, len(data) / STEIM_FRAME_SIZE, writer.options.Encoding, binary.BigEndian)
+		if err != nil {
+			return 0, err
+		}
+		copy(data, encoded)
+		return count, nil
+	}
+}
+
+
+func (writer *Writer) writeRecord(trace Trace, samples []int32, sampleIndex int) (int, error) {
+	recordLength := writer.options.RecordLength
+	record := make([]byte, recordLength)
+
+	samplesCount, err := writer.encodeData(samples, record[DATA_OFFSET:])
+	if err != nil {
+		return 0, err
+	}
+
+	factor, multiplier, err := sampleRateFactors(trace.SampleRate)
+	if err != nil {
+		return 0, err
+	}
+
+	offset := float64(sampleIndex) / trace.SampleRate * 1e9
+	recordStart := trace.StartTime.Add(time.Duration(math.Round(offset)))
+
+	writer.sequenceNumber = writer.sequenceNumber % 999999 + 1
+	copy(record[0:], fmt.Sprintf("%06d", writer.sequenceNumber))
+	record[6], record[7] = 'D', ' '
+	putCode(record[8:13], trace.Station)
+	putCode(record[13:15], trace.Location)
+	putCode(record[15:18], trace.Channel)
+	putCode(record[18:20], trace.Network)
+	putBtime(record[20:30], recordStart)
+	binary.BigEndian.PutUint16(record[30:], uint16(samplesCount))
+	binary.BigEndian.PutUint16(record[32:], uint16(factor))
+	binary.BigEndian.PutUint16(record[34:], uint16(multiplier))
+	record[39] = 2
+	binary.BigEndian.PutUint16(record[44:], DATA_OFFSET)
+	binary.BigEndian.PutUint16(record[46:], FIXED_HEADER_SIZE)
+
+	binary.BigEndian.PutUint16(record[48:], BLOCKETTE_1000)
+	binary.BigEndian.PutUint16(record[50:], FIXED_HEADER_SIZE + 8)
+	record[52] = writer.options.Encoding
+	record[53] = BIG_ENDIAN_WORD_ORDER
+	record[54] = recordLengthExponent(recordLength)
+
+	binary.BigEndian.PutUint16(record[56:], BLOCKETTE_1001)
+	record[61] = uint8(int8((recordStart.Nanosecond() / 1000) % 100))
+	if framesCount := (recordLength - DATA_OFFSET) / STEIM_FRAME_SIZE; framesCount <= math.MaxUint8 {
+		record[63] = uint8(framesCount)
+	}
+
+	if _, err := writer.writer.Write(record); err != nil {
+		return 0, err
+	}
+	return samplesCount, nil
+}
+
+
+func checkTraceId(trace Trace) error {
+	if len(trace.Channel) == 0 || len(trace.Channel) > 3 {
+		return InvalidParameter{message: fmt.Sprintf("Invalid channel code %q", trace.Channel)}
+	}
+	if len(trace.Station) > 5 || len(trace.Network) > 2 || len(trace.Location) > 2 {
+		return InvalidParameter{message: fmt.Sprintf("Invalid trace id %q", trace.Id())}
+	}
+	return nil
+}
+
+
+func (writer *Writer) WriteTrace(trace Trace) error {
+	if err := checkTraceId(trace); err != nil {
+		return err
+	}
+
+	for sampleIndex := 0; sampleIndex < len(trace.Samples); {
+		samplesCount, err := writer.writeRecord(trace, trace.Samples[sampleIndex:], sampleIndex)
+		if err != nil {
+			return err
+		}
+		sampleIndex += samplesCount
+	}
+	return nil
+}
+
+
+func (writer *Writer) WriteMultichannelTrace(trace binaryfile.MultichannelTrace, station string) error {
+	sampleRate := float64(trace.Frequency)
+	for i, component := range trace.Channels {
+		err := writer.WriteTrace(Trace{
+			Network: writer.options.Network,
+			Station: station,
+			Location: writer.options.Location,
+			Channel: writer.options.channelCode(component, i, sampleRate),
+			StartTime: trace.DatetimeStart,
+			SampleRate: sampleRate,
+			Samples: trace.Signals[i]})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+
+func (writer *Writer) writeStream(stream *binaryfile.SignalStream, trace Trace) error {
+	if err := checkTraceId(trace); err != nil {
+		return err
+	}
+
+	maxSamplesCount := writer.maxRecordSamplesCount()
+	sampleIndex := 0
+	pending := []int32{}
+	for isFinished := false; !isFinished; {
+		isFinished = !stream.Next()
+		if !isFinished {
+			pending = append(pending, stream.Block().Signals[0]...)
+		}
+
+		for len(pending) > 0 && (isFinished || len(pending) >= maxSamplesCount) {
+			samplesCount, err := writer.writeRecord(trace, pending, sampleIndex)
+			if err != nil {
+				return err
+			}
+			pending = pending[samplesCount:]
+			sampleIndex += samplesCount
+		}
+	}
+	return stream.Err()
+}
+
+
+func (writer *Writer) WriteBinaryFile(binFile binaryfile.BinaryFile) error {
+	recording, err := binFile.Open()
+	if err != nil {
+		return err
+	}
+	defer recording.Close()
+
+	datetimeStop, err := recording.DatetimeStop()
+	if err != nil {
+		return err
+	}
+
+	station := writer.options.Station
+	if len(station) == 0 {
+		station = recording.Header().StationName
+	}
+	if len(station) > 5 {
+		station = station[:5]
+	}
+
+	for i, component := range recording.ChannelNames() {
+		stream, err := recording.NewSignalStream(recording.DatetimeStart(), datetimeStop, []string{component}, 0)
+		if err != nil {
+			return err
+		}
+
+		sampleRate := float64(stream.Frequency())
+		trace := Trace{
+			Network: writer.options.Network,
+			Station: station,
+			Location: writer.options.Location,
+			Channel: writer.options.channelCode(component, i, sampleRate),
+			StartTime: stream.DatetimeStart(),
+			SampleRate: sampleRate}
+		if err := writer.writeStream(stream, trace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+
+func WriteBinaryFile(path string, binFile binaryfile.BinaryFile, options WriterOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	bufferedWriter := bufio.NewWriter(file)
+	writer, err := NewWriter(bufferedWriter, options)
+	if err == nil {
+		err = writer.WriteBinaryFile(binFile)
+	}
+	if err == nil {
+		err = bufferedWriter.Flush()
+	}
+
+	closeErr := file.Close()
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return closeErr
+}
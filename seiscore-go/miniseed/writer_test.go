@@ -0,0 +1,203 @@
+package miniseed
+
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+var TEST_RECORD_LENGTHS = []int{MIN_RECORD_LENGTH, 512, DEFAULT_RECORD_LENGTH}
+
+
+func samplesFromDifferences(first int32, differences []int64) []int32 {
+	samples := []int32{first}
+	value := int64(first)
+	for _, difference := range differences {
+		value += difference
+		samples = append(samples, int32(value))
+	}
+	return samples
+}
+
+
+func edgeDifferences(bitsWidths []uint) []int64 {
+	differences := []int64{}
+	for _, bits := range bitsWidths {
+		limit := int64(1) << (bits - 1)
+		for _, value := range []int64{limit - 1, -limit, limit, -limit - 1} {
+			repeatsCount := int64(7)
+			if maxRepeatsCount := math.MaxInt32 / (2 * (limit + 1)); maxRepeatsCount < repeatsCount {
+				repeatsCount = maxRepeatsCount
+			}
+			for i := int64(0); i < repeatsCount; i++ {
+				differences = append(differences, value)
+			}
+			for i := int64(0); i < repeatsCount; i++ {
+				differences = append(differences, -value)
+			}
+			differences = append(differences, value, 0, -value)
+		}
+	}
+	return differences
+}
+
+
+func frameBoundaryDifferences() []int64 {
+	differences := []int64{}
+	for i := 0; i < 500; i++ {
+		differences = append(differences, int64(7 - 15 * (i % 2)))
+	}
+	for i := 0; i < 200; i++ {
+		differences = append(differences, int64(511 * (1 - 2 * (i / 3 % 2))))
+	}
+	return differences
+}
+
+
+func roundTrip(t *testing.T, trace Trace, options WriterOptions) Trace {
+	buffer := &bytes.Buffer{}
+	writer, err := NewWriter(buffer, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteTrace(trace); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.Len() % options.RecordLength != 0 {
+		t.Fatalf("Written size %d is not a multiple of record length %d", buffer.Len(), options.RecordLength)
+	}
+
+	traces, err := ReadTraces(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 1 {
+		t.Fatalf("Read %d traces, want 1", len(traces))
+	}
+	return traces[0]
+}
+
+
+func checkTracesEqual(t *testing.T, result Trace, expected Trace) {
+	t.Helper()
+	if result.Id() != expected.Id() {
+		t.Errorf("Trace id %s, want %s", result.Id(), expected.Id())
+	}
+	if !result.StartTime.Equal(expected.StartTime) {
+		t.Errorf("Start time %s, want %s", result.StartTime, expected.StartTime)
+	}
+	if result.SampleRate != expected.SampleRate {
+		t.Errorf("Sample rate %g, want %g", result.SampleRate, expected.SampleRate)
+	}
+	if len(result.Samples) != len(expected.Samples) {
+		t.Fatalf("Samples count %d, want %d", len(result.Samples), len(expected.Samples))
+	}
+	for i := range expected.Samples {
+		if result.Samples[i] != expected.Samples[i] {
+			t.Fatalf("Sample %d is %d, want %d", i, result.Samples[i], expected.Samples[i])
+		}
+	}
+}
+
+
+func testTrace(samples []int32) Trace {
+	return Trace{
+		Network: "XX",
+		Station: "TEST",
+		Location: "00",
+		Channel: "HHZ",
+		StartTime: time.Date(2022, 1, 20, 8, 21, 5, 123000000, time.UTC),
+		SampleRate: 100,
+		Samples: samples}
+}
+
+
+func TestSteim1RoundTrip(t *testing.T) {
+	differences := edgeDifferences([]uint{8, 16})
+	differences = append(differences, frameBoundaryDifferences()...)
+	differences = append(differences, math.MinInt32, math.MaxInt32, 1)
+
+	trace := testTrace(samplesFromDifferences(0, differences))
+	for _, recordLength := range TEST_RECORD_LENGTHS {
+		options := WriterOptions{Encoding: STEIM1_ENCODING, RecordLength: recordLength}
+		checkTracesEqual(t, roundTrip(t, trace, options), trace)
+	}
+}
+
+
+func TestSteim2RoundTrip(t *testing.T) {
+	differences := edgeDifferences([]uint{4, 5, 6, 8, 10, 15})
+	differences = append(differences, frameBoundaryDifferences()...)
+	differences = append(differences, 1 << 29 - 1, -(1 << 29), 1)
+
+	trace := testTrace(samplesFromDifferences(-5, differences))
+	for _, recordLength := range TEST_RECORD_LENGTHS {
+		options := WriterOptions{Encoding: STEIM2_ENCODING, RecordLength: recordLength}
+		checkTracesEqual(t, roundTrip(t, trace, options), trace)
+	}
+}
+
+
+func TestSteim2DifferenceTooLarge(t *testing.T) {
+	trace := testTrace([]int32{0, 1 << 29})
+	writer, err := NewWriter(&bytes.Buffer{}, WriterOptions{Encoding: STEIM2_ENCODING})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteTrace(trace); err == nil {
+		t.Error("Difference wider than 30 bits is written without error")
+	}
+}
+
+
+func TestWriteBinaryFileRoundTrip(t *testing.T) {
+	samplesCount := binaryfile.BASE_MEMORY_BLOCK_SIZE / 12 + 25000
+	signals := make([][]int32, 3)
+	for i := range signals {
+		signals[i] = make([]int32, samplesCount)
+		for j := range signals[i] {
+			signals[i][j] = int32(math.Round(1e5 * math.Sin(float64(j * (i + 1)) / 50)))
+		}
+	}
+
+	header := binaryfile.FileHeader{
+		Frequency: 1000,
+		DatetimeStart: time.Date(2022, 1, 20, 8, 21, 5, 0, time.UTC),
+		Coordinate: binaryfile.Coordinate{Latitude: 55.5, Longitude: 84.25},
+		StationName: "TEST"}
+	directory := t.TempDir()
+	binaryPath := filepath.Join(directory, "record.00")
+	if err := binaryfile.WriteBinaryFile(binaryPath, binaryfile.BAIKAL7_FMT, header, signals); err != nil {
+		t.Fatal(err)
+	}
+
+	miniseedPath := filepath.Join(directory, "record.mseed")
+	options := WriterOptions{RecordLength: 512}
+	if err := WriteBinaryFile(miniseedPath, binaryfile.BinaryFile{Path: binaryPath}, options); err != nil {
+		t.Fatal(err)
+	}
+
+	traces, err := ReadFile(miniseedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != len(signals) {
+		t.Fatalf("Read %d traces, want %d", len(traces), len(signals))
+	}
+	for i, component := range binaryfile.COMPONENTS_ORDER {
+		expected := Trace{
+			Network: DEFAULT_NETWORK_CODE,
+			Station: "TEST",
+			Channel: ChannelCode(string(component), i, 1000),
+			StartTime: header.DatetimeStart,
+			SampleRate: 1000,
+			Samples: signals[i]}
+		checkTracesEqual(t, traces[i], expected)
+	}
+}
@@ -0,0 +1,23 @@
+package sac
+
+import (
+	"fmt"
+)
+
+
+type InvalidParameter struct {
+	message string
+}
+
+func (customError InvalidParameter) Error() string {
+	return fmt.Sprintf("InvalidParameter: %s", customError.message)
+}
+
+
+type BadHeaderData struct {
+	message string
+}
+
+func (customError BadHeaderData) Error() string {
+	return fmt.Sprintf("BadHeaderData: %s", customError.message)
+}
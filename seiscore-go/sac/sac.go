@@ -0,0 +1,367 @@
+package sac
+
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+const (
+	HEADER_SIZE = 632
+	FLOATS_COUNT, INTS_COUNT = 70, 40
+	INTS_OFFSET, STRINGS_OFFSET = 280, 440
+	HEADER_VERSION = 6
+	UNDEFINED_FLOAT = -12345.0
+	UNDEFINED_INT = -12345
+	UNDEFINED_STRING = "-12345"
+	ITIME, IUNKN, IB = 1, 5, 9
+)
+
+
+const (
+	DELTA_FIELD, DEPMIN_FIELD, DEPMAX_FIELD, SCALE_FIELD = 0, 1, 2, 3
+	B_FIELD, E_FIELD = 5, 6
+	STLA_FIELD, STLO_FIELD, STEL_FIELD = 31, 32, 33
+	DEPMEN_FIELD, CMPAZ_FIELD, CMPINC_FIELD = 56, 57, 58
+)
+
+
+const (
+	NZYEAR_FIELD, NZJDAY_FIELD, NZHOUR_FIELD, NZMIN_FIELD, NZSEC_FIELD, NZMSEC_FIELD = 0, 1, 2, 3, 4, 5
+	NVHDR_FIELD, NPTS_FIELD, IFTYPE_FIELD, IDEP_FIELD, IZTYPE_FIELD = 6, 9, 15, 16, 17
+	LEVEN_FIELD, LPSPOL_FIELD, LOVROK_FIELD, LCALDA_FIELD = 35, 36, 37, 38
+)
+
+
+const (
+	KSTNM_OFFSET, KCMPNM_OFFSET, KNETWK_OFFSET, KINST_OFFSET = 0, 160, 168, 184
+	SHORT_STRING_SIZE, LONG_STRING_SIZE = 8, 16
+)
+
+
+type Orientation struct {
+	Azimuth float64
+	Incidence float64
+}
+
+
+var COMPONENT_ORIENTATIONS = map[string]Orientation{
+	"Z": {Azimuth: 0, Incidence: 0},
+	"X": {Azimuth: 0, Incidence: 90},
+	"Y": {Azimuth: 90, Incidence: 90},
+}
+
+
+type Trace struct {
+	Network string
+	Station string
+	Component string
+	Instrument string
+	StartTime time.Time
+	SampleRate float64
+	Coordinate binaryfile.Coordinate
+	Elevation float64
+	Samples []float32
+}
+
+func (trace Trace) Delta() float64 {
+	return 1 / trace.SampleRate
+}
+
+func (trace Trace) Orientation() (Orientation, bool) {
+	orientation, isFound := COMPONENT_ORIENTATIONS[strings.ToUpper(trace.Component)]
+	return orientation, isFound
+}
+
+
+func NewTrace(binFile binaryfile.BinaryFile, timeStart time.Time, timeStop time.Time, component rune) (Trace, error) {
+	recording, err := binFile.Open()
+	if err != nil {
+		return Trace{}, err
+	}
+	defer recording.Close()
+
+	header := recording.Header()
+	multichannelTrace, err := recording.ReadSignals(timeStart, timeStop, []string{string(component)})
+	if err != nil {
+		return Trace{}, err
+	}
+
+	signal := multichannelTrace.Signals[0]
+	samples := make([]float32, len(signal))
+	for i, value := range signal {
+		samples[i] = float32(value)
+	}
+
+	return Trace{
+		Station: header.StationName,
+		Component: string(component),
+		StartTime: multichannelTrace.DatetimeStart,
+		SampleRate: float64(multichannelTrace.Frequency),
+		Coordinate: header.Coordinate,
+		Elevation: header.Gps.Altitude,
+		Samples: samples}, nil
+}
+
+
+type header struct {
+	floats [FLOATS_COUNT]float32
+	ints [INTS_COUNT]int32
+	strings [HEADER_SIZE - STRINGS_OFFSET]byte
+}
+
+func newHeader() header {
+	result := header{}
+	for i := range result.floats {
+		result.floats[i] = UNDEFINED_FLOAT
+	}
+	for i := range result.ints {
+		result.ints[i] = UNDEFINED_INT
+	}
+	for offset := 0; offset < len(result.strings); offset += SHORT_STRING_SIZE {
+		result.setString(offset, SHORT_STRING_SIZE, UNDEFINED_STRING)
+	}
+	result.setString(8, LONG_STRING_SIZE, UNDEFINED_STRING)
+	return result
+}
+
+func (sacHeader *header) setString(offset int, size int, value string) {
+	field := sacHeader.strings[offset:offset + size]
+	for i := range field {
+		field[i] = ' '
+	}
+	copy(field, value)
+}
+
+func (sacHeader header) getString(offset int, size int) string {
+	value := strings.TrimRight(string(sacHeader.strings[offset:offset + size]), " \x00")
+	if value == UNDEFINED_STRING {
+		return ""
+	}
+	return value
+}
+
+func (sacHeader header) getFloat(index int) (float64, bool) {
+	value := sacHeader.floats[index]
+	return float64(value), value != UNDEFINED_FLOAT
+}
+
+
+func encodeHeader(trace Trace) (header, error) {
+	if trace.SampleRate <= 0 {
+		return header{}, InvalidParameter{message: "Invalid sample rate"}
+	}
+
+	result := newHeader()
+	startTime := trace.StartTime.UTC()
+	referenceTime := startTime.Truncate(time.Millisecond)
+	begin := startTime.Sub(referenceTime).Seconds()
+	delta := trace.Delta()
+
+	result.floats[DELTA_FIELD] = float32(delta)
+	result.floats[SCALE_FIELD] = 1
+	result.floats[B_FIELD] = float32(begin)
+	result.floats[E_FIELD] = float32(begin + float64(len(trace.Samples) - 1) * delta)
+	result.floats[STLA_FIELD] = float32(trace.Coordinate.Latitude)
+	result.floats[STLO_FIELD] = float32(trace.Coordinate.Longitude)
+	result.floats[STEL_FIELD] = float32(trace.Elevation)
+
+	if len(trace.Samples) > 0 {
+		minValue, maxValue, sumValue := math.Inf(1), math.Inf(-1), 0.0
+		for _, value := range trace.Samples {
+			minValue = math.Min(minValue, float64(value))
+			maxValue = math.Max(maxValue, float64(value))
+			sumValue += float64(value)
+		}
+		result.floats[DEPMIN_FIELD] = float32(minValue)
+		result.floats[DEPMAX_FIELD] = float32(maxValue)
+		result.floats[DEPMEN_FIELD] = float32(sumValue / float64(len(trace.Samples)))
+	}
+
+	if orientation, isFound := trace.Orientation(); isFound {
+		result.floats[CMPAZ_FIELD] = float32(orientation.Azimuth)
+		result.floats[CMPINC_FIELD] = float32(orientation.Incidence)
+	}
+
+	result.ints[NZYEAR_FIELD] = int32(referenceTime.Year())
+	result.ints[NZJDAY_FIELD] = int32(referenceTime.YearDay())
+	result.ints[NZHOUR_FIELD] = int32(referenceTime.Hour())
+	result.ints[NZMIN_FIELD] = int32(referenceTime.Minute())
+	result.ints[NZSEC_FIELD] = int32(referenceTime.Second())
+	result.ints[NZMSEC_FIELD] = int32(referenceTime.Nanosecond() / 1e6)
+	result.ints[NVHDR_FIELD] = HEADER_VERSION
+	result.ints[NPTS_FIELD] = int32(len(trace.Samples))
+	result.ints[IFTYPE_FIELD] = ITIME
+	result.ints[IDEP_FIELD] = IUNKN
+	result.ints[IZTYPE_FIELD] = IB
+	result.ints[LEVEN_FIELD] = 1
+	result.ints[LPSPOL_FIELD] = 1
+	result.ints[LOVROK_FIELD] = 1
+	result.ints[LCALDA_FIELD] = 1
+
+	if len(trace.Station) != 0 {
+		result.setString(KSTNM_OFFSET, SHORT_STRING_SIZE, trace.Station)
+	}
+	if len(trace.Component) != 0 {
+		result.setString(KCMPNM_OFFSET, SHORT_STRING_SIZE, trace.Component)
+	}
+	if len(trace.Network) != 0 {
+		result.setString(KNETWK_OFFSET, SHORT_STRING_SIZE, trace.Network)
+	}
+	if len(trace.Instrument) != 0 {
+		result.setString(KINST_OFFSET, SHORT_STRING_SIZE, trace.Instrument)
+	}
+	return result, nil
+}
+
+
+func Write(writer io.Writer, trace Trace) error {
+	sacHeader, err := encodeHeader(trace)
+	if err != nil {
+		return err
+	}
+
+	if err := binary.Write(writer, binary.LittleEndian, sacHeader.floats); err != nil {
+		return err
+	}
+	if err := binary.Write(writer, binary.LittleEndian, sacHeader.ints); err != nil {
+		return err
+	}
+	if _, err := writer.Write(sacHeader.strings[:]); err != nil {
+		return err
+	}
+	return binary.Write(writer, binary.LittleEndian, trace.Samples)
+}
+
+
+func WriteFile(path string, trace Trace) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	err = Write(writer, trace)
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	closeErr := file.Close()
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return closeErr
+}
+
+
+func headerByteOrder(buffer []byte) (binary.ByteOrder, error) {
+	offset := INTS_OFFSET + 4 * NVHDR_FIELD
+	if binary.LittleEndian.Uint32(buffer[offset:]) == HEADER_VERSION {
+		return binary.LittleEndian, nil
+	}
+	if binary.BigEndian.Uint32(buffer[offset:]) == HEADER_VERSION {
+		return binary.BigEndian, nil
+	}
+	return nil, BadHeaderData{message: "Unsupported header version"}
+}
+
+
+func decodeHeader(buffer []byte) (header, binary.ByteOrder, error) {
+	order, err := headerByteOrder(buffer)
+	if err != nil {
+		return header{}, nil, err
+	}
+
+	result := header{}
+	for i := range result.floats {
+		result.floats[i] = math.Float32frombits(order.Uint32(buffer[4 * i:]))
+	}
+	for i := range result.ints {
+		result.ints[i] = int32(order.Uint32(buffer[INTS_OFFSET + 4 * i:]))
+	}
+	copy(result.strings[:], buffer[STRINGS_OFFSET:])
+	return result, order, nil
+}
+
+
+func sampleRateFromDelta(delta float64) float64 {
+	sampleRate := 1 / delta
+	roundedRate := math.Round(sampleRate)
+	if math.Abs(sampleRate - roundedRate) <= roundedRate * 1e-6 {
+		return roundedRate
+	}
+	return sampleRate
+}
+
+
+func Read(reader io.Reader) (Trace, error) {
+	buffer := make([]byte, HEADER_SIZE)
+	if _, err := io.ReadFull(reader, buffer); err != nil {
+		return Trace{}, BadHeaderData{message: "File is shorter than header"}
+	}
+
+	sacHeader, order, err := decodeHeader(buffer)
+	if err != nil {
+		return Trace{}, err
+	}
+
+	delta, isDefined := sacHeader.getFloat(DELTA_FIELD)
+	if !isDefined || delta <= 0 {
+		return Trace{}, BadHeaderData{message: "Invalid delta"}
+	}
+
+	if sacHeader.ints[LEVEN_FIELD] != 1 || sacHeader.ints[IFTYPE_FIELD] != ITIME {
+		return Trace{}, BadHeaderData{message: "Only evenly sampled time series are supported"}
+	}
+
+	samplesCount := int(sacHeader.ints[NPTS_FIELD])
+	if samplesCount < 0 {
+		return Trace{}, BadHeaderData{message: "Invalid samples count"}
+	}
+
+	samples := make([]float32, samplesCount)
+	if err := binary.Read(reader, order, samples); err != nil {
+		return Trace{}, BadHeaderData{message: "File is shorter than samples count"}
+	}
+
+	referenceTime := time.Date(
+		int(sacHeader.ints[NZYEAR_FIELD]), time.January, int(sacHeader.ints[NZJDAY_FIELD]),
+		int(sacHeader.ints[NZHOUR_FIELD]), int(sacHeader.ints[NZMIN_FIELD]),
+		int(sacHeader.ints[NZSEC_FIELD]), int(sacHeader.ints[NZMSEC_FIELD]) * 1e6, time.UTC)
+	begin, _ := sacHeader.getFloat(B_FIELD)
+	startTime := referenceTime.Add(time.Duration(math.Round(begin * 1e9)))
+
+	latitude, _ := sacHeader.getFloat(STLA_FIELD)
+	longitude, _ := sacHeader.getFloat(STLO_FIELD)
+	elevation, _ := sacHeader.getFloat(STEL_FIELD)
+
+	return Trace{
+		Network: sacHeader.getString(KNETWK_OFFSET, SHORT_STRING_SIZE),
+		Station: sacHeader.getString(KSTNM_OFFSET, SHORT_STRING_SIZE),
+		Component: sacHeader.getString(KCMPNM_OFFSET, SHORT_STRING_SIZE),
+		Instrument: sacHeader.getString(KINST_OFFSET, SHORT_STRING_SIZE),
+		StartTime: startTime,
+		SampleRate: sampleRateFromDelta(delta),
+		Coordinate: binaryfile.Coordinate{Longitude: longitude, Latitude: latitude},
+		Elevation: elevation,
+		Samples: samples}, nil
+}
+
+
+func ReadFile(path string) (Trace, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Trace{}, err
+	}
+	defer file.Close()
+	return Read(bufio.NewReader(file))
+}
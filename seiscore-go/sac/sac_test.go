@@ -0,0 +1,130 @@
+package sac
+
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+func testTrace() Trace {
+	samples := make([]float32, 1000)
+	for i := range samples {
+		samples[i] = float32(1000 * math.Sin(float64(i) / 10))
+	}
+	return Trace{
+		Network: "XX",
+		Station: "TEST",
+		Component: "Z",
+		StartTime: time.Date(2022, 1, 20, 8, 21, 5, 123400000, time.UTC),
+		SampleRate: 100,
+		Coordinate: binaryfile.Coordinate{Latitude: 55.5, Longitude: 84.25},
+		Elevation: 312.5,
+		Samples: samples}
+}
+
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	trace := testTrace()
+	buffer := &bytes.Buffer{}
+	if err := Write(buffer, trace); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.Len() != HEADER_SIZE + 4 * len(trace.Samples) {
+		t.Fatalf("Written size %d, want %d", buffer.Len(), HEADER_SIZE + 4 * len(trace.Samples))
+	}
+
+	sacHeader, _, err := decodeHeader(buffer.Bytes()[:HEADER_SIZE])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	floatFields := []struct {
+		name string
+		index int
+		expected float64
+	}{
+		{"delta", DELTA_FIELD, 0.01},
+		{"b", B_FIELD, 0.0004},
+		{"e", E_FIELD, 0.0004 + 999 * 0.01},
+		{"stla", STLA_FIELD, 55.5},
+		{"stlo", STLO_FIELD, 84.25},
+	}
+	for _, field := range floatFields {
+		value, isDefined := sacHeader.getFloat(field.index)
+		if !isDefined || math.Abs(value - field.expected) > 1e-6 * math.Max(1, math.Abs(field.expected)) {
+			t.Errorf("%s is %g, want %g", field.name, value, field.expected)
+		}
+	}
+
+	intFields := []struct {
+		name string
+		index int
+		expected int32
+	}{
+		{"npts", NPTS_FIELD, 1000},
+		{"nzyear", NZYEAR_FIELD, 2022},
+		{"nzjday", NZJDAY_FIELD, 20},
+		{"nzhour", NZHOUR_FIELD, 8},
+		{"nzmin", NZMIN_FIELD, 21},
+		{"nzsec", NZSEC_FIELD, 5},
+		{"nzmsec", NZMSEC_FIELD, 123},
+	}
+	for _, field := range intFields {
+		if sacHeader.ints[field.index] != field.expected {
+			t.Errorf("%s is %d, want %d", field.name, sacHeader.ints[field.index], field.expected)
+		}
+	}
+
+	if station := sacHeader.getString(KSTNM_OFFSET, SHORT_STRING_SIZE); station != "TEST" {
+		t.Errorf("kstnm is %q, want TEST", station)
+	}
+	if component := sacHeader.getString(KCMPNM_OFFSET, SHORT_STRING_SIZE); component != "Z" {
+		t.Errorf("kcmpnm is %q, want Z", component)
+	}
+
+	result, err := Read(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Network != trace.Network || result.Station != trace.Station || result.Component != trace.Component {
+		t.Errorf("Trace id %s.%s.%s", result.Network, result.Station, result.Component)
+	}
+	if !result.StartTime.Equal(trace.StartTime) {
+		t.Errorf("Start time %s, want %s", result.StartTime, trace.StartTime)
+	}
+	if result.SampleRate != trace.SampleRate {
+		t.Errorf("Sample rate %g, want %g", result.SampleRate, trace.SampleRate)
+	}
+	if result.Coordinate != trace.Coordinate || result.Elevation != trace.Elevation {
+		t.Errorf("Location %+v %g, want %+v %g", result.Coordinate, result.Elevation, trace.Coordinate, trace.Elevation)
+	}
+	if len(result.Samples) != len(trace.Samples) {
+		t.Fatalf("Samples count %d, want %d", len(result.Samples), len(trace.Samples))
+	}
+	for i := range trace.Samples {
+		if result.Samples[i] != trace.Samples[i] {
+			t.Fatalf("Sample %d is %g, want %g", i, result.Samples[i], trace.Samples[i])
+		}
+	}
+}
+
+
+func TestReadRejectsBadDelta(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := Write(buffer, testTrace()); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buffer.Bytes()
+	for i := 0; i < 4; i++ {
+		data[4 * DELTA_FIELD + i] = 0
+	}
+	if _, err := Read(bytes.NewReader(data)); err == nil {
+		t.Error("Zero delta is read without error")
+	}
+}
@@ -0,0 +1,32 @@
+package segy
+
+import (
+	"fmt"
+)
+
+
+type InvalidParameter struct {
+	message string
+}
+
+func (customError InvalidParameter) Error() string {
+	return fmt.Sprintf("InvalidParameter: %s", customError.message)
+}
+
+
+type BadHeaderData struct {
+	message string
+}
+
+func (customError BadHeaderData) Error() string {
+	return fmt.Sprintf("BadHeaderData: %s", customError.message)
+}
+
+
+type BadTraceData struct {
+	message string
+}
+
+func (customError BadTraceData) Error() string {
+	return fmt.Sprintf("BadTraceData: %s", customError.message)
+}
@@ -0,0 +1,167 @@
+package segy
+
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+func isEbcdicText(text []byte) bool {
+	return text[0] == asciiToEbcdic['C']
+}
+
+
+func applyScalar(value int32, scalar int16) float64 {
+	switch {
+	case scalar > 0:
+		return float64(value) * float64(scalar)
+	case scalar < 0:
+		return float64(value) / float64(-scalar)
+	default:
+		return float64(value)
+	}
+}
+
+
+func fromArcSeconds(value int32, scalar int16, units int16) float64 {
+	scaled := applyScalar(value, scalar)
+	if units == ARC_SECONDS_UNITS {
+		return scaled / 3600
+	}
+	return scaled
+}
+
+
+func decodeTraceHeader(buffer []byte) TraceHeader {
+	elevationScalar := int16(binary.BigEndian.Uint16(buffer[68:]))
+	coordinateScalar := int16(binary.BigEndian.Uint16(buffer[70:]))
+	units := int16(binary.BigEndian.Uint16(buffer[88:]))
+
+	shotSecond := time.Date(
+		int(binary.BigEndian.Uint16(buffer[156:])), time.January,
+		int(binary.BigEndian.Uint16(buffer[158:])),
+		int(binary.BigEndian.Uint16(buffer[160:])),
+		int(binary.BigEndian.Uint16(buffer[162:])),
+		int(binary.BigEndian.Uint16(buffer[164:])), 0, time.UTC)
+	lagTime := time.Duration(int16(binary.BigEndian.Uint16(buffer[106:]))) * time.Millisecond
+	delayTime := time.Duration(int16(binary.BigEndian.Uint16(buffer[108:]))) * time.Millisecond
+	shotTime := shotSecond.Add(lagTime)
+
+	return TraceHeader{
+		SequenceNumber: int32(binary.BigEndian.Uint32(buffer[4:])),
+		FieldRecord: int32(binary.BigEndian.Uint32(buffer[8:])),
+		TraceNumber: int32(binary.BigEndian.Uint32(buffer[12:])),
+		Coordinate: binaryfile.Coordinate{
+			Longitude: fromArcSeconds(int32(binary.BigEndian.Uint32(buffer[80:])), coordinateScalar, units),
+			Latitude: fromArcSeconds(int32(binary.BigEndian.Uint32(buffer[84:])), coordinateScalar, units)},
+		Elevation: applyScalar(int32(binary.BigEndian.Uint32(buffer[40:])), elevationScalar),
+		SampleInterval: time.Duration(binary.BigEndian.Uint16(buffer[116:])) * time.Microsecond,
+		SamplesCount: int(binary.BigEndian.Uint16(buffer[114:])),
+		ShotTime: shotTime,
+		DatetimeStart: shotTime.Add(delayTime)}
+}
+
+
+func decodeSamples(buffer []byte, sampleFormat int) []float64 {
+	samples := make([]float64, len(buffer) / 4)
+	for i := range samples {
+		word := binary.BigEndian.Uint32(buffer[4 * i:])
+		if sampleFormat == FLOAT32_FORMAT {
+			samples[i] = float64(math.Float32frombits(word))
+		} else {
+			samples[i] = float64(int32(word))
+		}
+	}
+	return samples
+}
+
+
+// The samples count comes from the file, so the trace data buffer grows with
+// the data actually read instead of being allocated from the count up front.
+func readTraceData(reader io.Reader, samplesCount int) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	if _, err := io.CopyN(&buffer, reader, 4 * int64(samplesCount)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+
+func Read(reader io.Reader) (File, error) {
+	text := make([]byte, TEXTUAL_HEADER_SIZE)
+	if _, err := io.ReadFull(reader, text); err != nil {
+		return File{}, BadHeaderData{message: "File is shorter than textual header"}
+	}
+	if isEbcdicText(text) {
+		text = decodeEbcdic(text)
+	}
+
+	binaryHeader := make([]byte, BINARY_HEADER_SIZE)
+	if _, err := io.ReadFull(reader, binaryHeader); err != nil {
+		return File{}, BadHeaderData{message: "File is shorter than binary header"}
+	}
+
+	result := File{
+		TextualHeader: parseTextualHeader(text),
+		Revision: int(binaryHeader[300]),
+		SampleFormat: int(binary.BigEndian.Uint16(binaryHeader[24:])),
+		SampleInterval: time.Duration(binary.BigEndian.Uint16(binaryHeader[16:])) * time.Microsecond,
+		SamplesCount: int(binary.BigEndian.Uint16(binaryHeader[20:]))}
+
+	if result.SampleFormat != INT32_FORMAT && result.SampleFormat != FLOAT32_FORMAT {
+		return File{}, BadHeaderData{message: "Unsupported sample format"}
+	}
+
+	if binary.BigEndian.Uint16(binaryHeader[304:]) != 0 {
+		return File{}, BadHeaderData{message: "Extended textual headers are not supported"}
+	}
+
+	if result.Revision >= REVISION_2 {
+		if extendedCount := binary.BigEndian.Uint32(binaryHeader[68:]); extendedCount != 0 {
+			result.SamplesCount = int(extendedCount)
+		}
+	}
+
+	traceHeaderBuffer := make([]byte, TRACE_HEADER_SIZE)
+	for {
+		_, err := io.ReadFull(reader, traceHeaderBuffer)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return File{}, BadTraceData{message: "Truncated trace header"}
+		}
+
+		traceHeader := decodeTraceHeader(traceHeaderBuffer)
+		if traceHeader.SamplesCount == 0 {
+			traceHeader.SamplesCount = result.SamplesCount
+		}
+
+		data, err := readTraceData(reader, traceHeader.SamplesCount)
+		if err != nil {
+			return File{}, BadTraceData{message: "Truncated trace data"}
+		}
+
+		result.Traces = append(result.Traces, Trace{
+			Header: traceHeader,
+			Samples: decodeSamples(data, result.SampleFormat)})
+	}
+}
+
+
+func ReadFile(path string) (File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer file.Close()
+	return Read(bufio.NewReader(file))
+}
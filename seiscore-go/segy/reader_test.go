@@ -0,0 +1,147 @@
+package segy
+
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+func writeTestRecording(t *testing.T) (binaryfile.BinaryFile, binaryfile.FileHeader) {
+	signals := make([][]int32, 3)
+	for i := range signals {
+		signals[i] = make([]int32, 10000)
+		for j := range signals[i] {
+			signals[i][j] = int32(math.Round(1e5 * math.Sin(float64(j * (i + 1)) / 50)))
+		}
+	}
+
+	header := binaryfile.FileHeader{
+		Frequency: 1000,
+		DatetimeStart: time.Date(2022, 1, 20, 8, 21, 5, 0, time.UTC),
+		Coordinate: binaryfile.Coordinate{Latitude: 55.123456, Longitude: -84.654321},
+		StationName: "TEST",
+		Gps: binaryfile.GpsInfo{Altitude: 312.5}}
+	path := filepath.Join(t.TempDir(), "record.00")
+	if err := binaryfile.WriteBinaryFile(path, binaryfile.BAIKAL7_FMT, header, signals); err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := binaryfile.OpenRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recording.Close()
+	return binaryfile.BinaryFile{Path: path}, recording.Header()
+}
+
+
+func checkRoundTrip(t *testing.T, options WriterOptions) {
+	binFile, header := writeTestRecording(t)
+	windows := []Window{
+		{
+			BinaryFile: binFile,
+			ShotTime: header.DatetimeStart.Add(2250 * time.Millisecond),
+			TimeBefore: 100 * time.Millisecond,
+			TimeAfter: 2 * time.Second},
+		{
+			BinaryFile: binFile,
+			ShotTime: header.DatetimeStart.Add(5 * time.Second + 875 * time.Millisecond),
+			ShotNumber: 7,
+			TimeBefore: 100 * time.Millisecond,
+			TimeAfter: 2 * time.Second},
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := Write(buffer, windows, options); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Read(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Revision != options.Revision || result.SampleFormat != options.SampleFormat {
+		t.Errorf("Revision %d format %d, want %d %d", result.Revision, result.SampleFormat, options.Revision, options.SampleFormat)
+	}
+	if !strings.HasPrefix(result.TextualHeader[0], "C 1 SEISCORE SEG-Y EXPORT") {
+		t.Errorf("Textual header starts with %q", result.TextualHeader[0])
+	}
+	if result.SampleInterval != time.Millisecond || result.SamplesCount != 2100 {
+		t.Errorf("Sample interval %s samples count %d", result.SampleInterval, result.SamplesCount)
+	}
+	if len(result.Traces) != 6 {
+		t.Fatalf("Read %d traces, want 6", len(result.Traces))
+	}
+
+	for i, window := range windows {
+		expected, err := binFile.ReadSignals(window.ShotTime.Add(-window.TimeBefore), window.ShotTime.Add(window.TimeAfter), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for j, signal := range expected.Signals {
+			trace := result.Traces[3 * i + j]
+			traceHeader := trace.Header
+			if traceHeader.SequenceNumber != int32(3 * i + j + 1) || traceHeader.TraceNumber != int32(j + 1) {
+				t.Errorf("Trace %d numbers %d %d", 3 * i + j, traceHeader.SequenceNumber, traceHeader.TraceNumber)
+			}
+			if i == 1 && traceHeader.FieldRecord != 7 {
+				t.Errorf("Field record %d, want 7", traceHeader.FieldRecord)
+			}
+			if math.Abs(traceHeader.Coordinate.Latitude - header.Coordinate.Latitude) > 1.0 / 360000 ||
+				math.Abs(traceHeader.Coordinate.Longitude - header.Coordinate.Longitude) > 1.0 / 360000 {
+				t.Errorf("Coordinate %+v, want %+v", traceHeader.Coordinate, header.Coordinate)
+			}
+			if traceHeader.Elevation != header.Gps.Altitude {
+				t.Errorf("Elevation %g, want %g", traceHeader.Elevation, header.Gps.Altitude)
+			}
+			if !traceHeader.ShotTime.Equal(window.ShotTime) {
+				t.Errorf("Shot time %s, want %s", traceHeader.ShotTime, window.ShotTime)
+			}
+			if !traceHeader.DatetimeStart.Equal(expected.DatetimeStart) {
+				t.Errorf("Trace start %s, want %s", traceHeader.DatetimeStart, expected.DatetimeStart)
+			}
+			if len(trace.Samples) != len(signal) {
+				t.Fatalf("Samples count %d, want %d", len(trace.Samples), len(signal))
+			}
+			for k := range signal {
+				if trace.Samples[k] != float64(signal[k]) {
+					t.Fatalf("Trace %d sample %d is %g, want %d", 3 * i + j, k, trace.Samples[k], signal[k])
+				}
+			}
+		}
+	}
+}
+
+
+func TestRevision1RoundTrip(t *testing.T) {
+	checkRoundTrip(t, WriterOptions{Revision: REVISION_1, SampleFormat: INT32_FORMAT})
+}
+
+
+func TestRevision2RoundTrip(t *testing.T) {
+	checkRoundTrip(t, WriterOptions{Revision: REVISION_2, SampleFormat: FLOAT32_FORMAT})
+}
+
+
+func TestReadTruncatedExtendedSamplesCount(t *testing.T) {
+	binaryHeader := make([]byte, BINARY_HEADER_SIZE)
+	binary.BigEndian.PutUint16(binaryHeader[24:], INT32_FORMAT)
+	binary.BigEndian.PutUint32(binaryHeader[68:], math.MaxUint32)
+	binaryHeader[300] = REVISION_2
+
+	data := append([]byte(strings.Repeat(" ", TEXTUAL_HEADER_SIZE)), binaryHeader...)
+	data = append(data, make([]byte, TRACE_HEADER_SIZE + 16)...)
+	if _, err := Read(bytes.NewReader(data)); !errors.As(err, &BadTraceData{}) {
+		t.Errorf("Truncated trace with huge samples count: %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+package segy
+
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+type Window struct {
+	BinaryFile binaryfile.BinaryFile
+	ShotTime time.Time
+	ShotNumber int32
+	TimeBefore time.Duration
+	TimeAfter time.Duration
+	Components []string
+}
+
+
+type WriterOptions struct {
+	Revision int
+	SampleFormat int
+	LineNumber int32
+	Description []string
+}
+
+func (options WriterOptions) withDefaults() (WriterOptions, error) {
+	if options.Revision == 0 {
+		options.Revision = REVISION_1
+	}
+	if options.SampleFormat == 0 {
+		options.SampleFormat = INT32_FORMAT
+	}
+
+	if options.Revision != REVISION_1 && options.Revision != REVISION_2 {
+		return options, InvalidParameter{message: fmt.Sprintf("Unsupported revision %d", options.Revision)}
+	}
+	if options.SampleFormat != INT32_FORMAT && options.SampleFormat != FLOAT32_FORMAT {
+		return options, InvalidParameter{message: fmt.Sprintf("Unsupported sample format %d", options.SampleFormat)}
+	}
+	return options, nil
+}
+
+
+type windowTraces struct {
+	window Window
+	header binaryfile.FileHeader
+	trace binaryfile.MultichannelTrace
+}
+
+
+func readWindow(window Window, shotIndex int) (windowTraces, error) {
+	recording, err := window.BinaryFile.Open()
+	if err != nil {
+		return windowTraces{}, err
+	}
+	defer recording.Close()
+
+	timeStart := window.ShotTime.Add(-window.TimeBefore)
+	timeStop := window.ShotTime.Add(window.TimeAfter)
+	trace, err := recording.ReadSignals(timeStart, timeStop, window.Components)
+	if err != nil {
+		return windowTraces{}, err
+	}
+
+	if window.ShotNumber == 0 {
+		window.ShotNumber = int32(shotIndex + 1)
+	}
+	return windowTraces{window: window, header: recording.Header(), trace: trace}, nil
+}
+
+
+func textualHeaderLines(options WriterOptions, windows []windowTraces, samplesCount int, sampleInterval int) []string {
+	formatName := "4-BYTE TWO'S COMPLEMENT INTEGER"
+	if options.SampleFormat == FLOAT32_FORMAT {
+		formatName = "4-BYTE IEEE FLOATING POINT"
+	}
+
+	channels := []string{}
+	for i, name := range windows[0].trace.Channels {
+		channels = append(channels, fmt.Sprintf("%d=%s", i + 1, name))
+	}
+
+	content := []string{
+		"SEISCORE SEG-Y EXPORT",
+		fmt.Sprintf("LINE %d  SHOTS %d  TRACES PER SHOT %d", options.LineNumber, len(windows), len(channels)),
+		fmt.Sprintf("SAMPLES PER TRACE %d  SAMPLE INTERVAL %d US", samplesCount, sampleInterval),
+		fmt.Sprintf("SAMPLE FORMAT %s", formatName),
+		fmt.Sprintf("TRACE NUMBER TO CHANNEL: %s", strings.Join(channels, " ")),
+		"RECEIVER GROUP X/Y: LONGITUDE/LATITUDE IN ARC SECONDS, SCALAR -100",
+		"TIMES UTC: SHOT = TRACE TIME + LAG TIME B, RECORD START = SHOT + DELAY",
+	}
+	content = append(content, options.Description...)
+
+	lines := make([]string, TEXTUAL_LINES_COUNT)
+	for i := range lines {
+		text := ""
+		if i < len(content) && i < TEXTUAL_LINES_COUNT - 2 {
+			text = content[i]
+		}
+		lines[i] = fmt.Sprintf("C%2d %s", i + 1, strings.ToUpper(text))
+	}
+
+	if options.Revision == REVISION_2 {
+		lines[TEXTUAL_LINES_COUNT - 2] = fmt.Sprintf("C%2d SEG-Y_REV2.0", TEXTUAL_LINES_COUNT - 1)
+		lines[TEXTUAL_LINES_COUNT - 1] = fmt.Sprintf("C%2d END TEXTUAL HEADER", TEXTUAL_LINES_COUNT)
+	} else {
+		lines[TEXTUAL_LINES_COUNT - 2] = fmt.Sprintf("C%2d SEG Y REV1", TEXTUAL_LINES_COUNT - 1)
+		lines[TEXTUAL_LINES_COUNT - 1] = fmt.Sprintf("C%2d END EBCDIC", TEXTUAL_LINES_COUNT)
+	}
+	return lines
+}
+
+
+func encodeBinaryHeader(options WriterOptions, tracesPerShot int, samplesCount int, sampleInterval int, tracesCount int) []byte {
+	buffer := make([]byte, BINARY_HEADER_SIZE)
+	binary.BigEndian.PutUint32(buffer[0:], 1)
+	binary.BigEndian.PutUint32(buffer[4:], uint32(options.LineNumber))
+	binary.BigEndian.PutUint32(buffer[8:], 1)
+	binary.BigEndian.PutUint16(buffer[12:], uint16(tracesPerShot))
+	binary.BigEndian.PutUint16(buffer[16:], uint16(sampleInterval))
+	binary.BigEndian.PutUint16(buffer[18:], uint16(sampleInterval))
+	binary.BigEndian.PutUint16(buffer[20:], uint16(samplesCount))
+	binary.BigEndian.PutUint16(buffer[22:], uint16(samplesCount))
+	binary.BigEndian.PutUint16(buffer[24:], uint16(options.SampleFormat))
+	binary.BigEndian.PutUint16(buffer[26:], 1)
+	binary.BigEndian.PutUint16(buffer[28:], AS_RECORDED_SORTING)
+	binary.BigEndian.PutUint16(buffer[54:], METERS_MEASUREMENT)
+	buffer[300] = byte(options.Revision)
+	binary.BigEndian.PutUint16(buffer[302:], 1)
+
+	if options.Revision == REVISION_2 {
+		binary.BigEndian.PutUint32(buffer[68:], uint32(samplesCount))
+		binary.BigEndian.PutUint64(buffer[72:], math.Float64bits(float64(sampleInterval)))
+		binary.BigEndian.PutUint32(buffer[96:], 0x01020304)
+		binary.BigEndian.PutUint16(buffer[310:], UTC_TIME_BASIS)
+		binary.BigEndian.PutUint64(buffer[312:], uint64(tracesCount))
+		binary.BigEndian.PutUint64(buffer[320:], TEXTUAL_HEADER_SIZE + BINARY_HEADER_SIZE)
+	}
+	return buffer
+}
+
+
+func putShort(buffer []byte, offset int, value int16) {
+	binary.BigEndian.PutUint16(buffer[offset:], uint16(value))
+}
+
+
+func toArcSeconds(degrees float64) uint32 {
+	return uint32(int32(math.Round(degrees * 3600 * 100)))
+}
+
+
+func encodeTraceHeader(header TraceHeader) ([]byte, error) {
+	shotSecond := header.ShotTime.UTC().Truncate(time.Second)
+	lagTime := header.ShotTime.Sub(shotSecond).Milliseconds()
+	delayTime := header.DatetimeStart.Sub(header.ShotTime).Milliseconds()
+	if delayTime < math.MinInt16 || delayTime > math.MaxInt16 {
+		return nil, InvalidParameter{message: "Recording delay relative to shot does not fit trace header"}
+	}
+
+	buffer := make([]byte, TRACE_HEADER_SIZE)
+	binary.BigEndian.PutUint32(buffer[0:], uint32(header.SequenceNumber))
+	binary.BigEndian.PutUint32(buffer[4:], uint32(header.SequenceNumber))
+	binary.BigEndian.PutUint32(buffer[8:], uint32(header.FieldRecord))
+	binary.BigEndian.PutUint32(buffer[12:], uint32(header.TraceNumber))
+	binary.BigEndian.PutUint32(buffer[16:], uint32(header.FieldRecord))
+	binary.BigEndian.PutUint16(buffer[28:], SEISMIC_TRACE_ID)
+	binary.BigEndian.PutUint16(buffer[34:], 1)
+	binary.BigEndian.PutUint32(buffer[40:], uint32(int32(math.Round(header.Elevation * 100))))
+	putShort(buffer, 68, ELEVATION_SCALAR)
+	putShort(buffer, 70, COORDINATE_SCALAR)
+	binary.BigEndian.PutUint32(buffer[80:], toArcSeconds(header.Coordinate.Longitude))
+	binary.BigEndian.PutUint32(buffer[84:], toArcSeconds(header.Coordinate.Latitude))
+	binary.BigEndian.PutUint16(buffer[88:], ARC_SECONDS_UNITS)
+	putShort(buffer, 106, int16(lagTime))
+	putShort(buffer, 108, int16(delayTime))
+	binary.BigEndian.PutUint16(buffer[114:], uint16(header.SamplesCount))
+	binary.BigEndian.PutUint16(buffer[116:], uint16(header.SampleInterval.Microseconds()))
+	binary.BigEndian.PutUint16(buffer[156:], uint16(shotSecond.Year()))
+	binary.BigEndian.PutUint16(buffer[158:], uint16(shotSecond.YearDay()))
+	binary.BigEndian.PutUint16(buffer[160:], uint16(shotSecond.Hour()))
+	binary.BigEndian.PutUint16(buffer[162:], uint16(shotSecond.Minute()))
+	binary.BigEndian.PutUint16(buffer[164:], uint16(shotSecond.Second()))
+	binary.BigEndian.PutUint16(buffer[166:], UTC_TIME_BASIS)
+	return buffer, nil
+}
+
+
+func encodeSamples(signal []int32, sampleFormat int) []byte {
+	buffer := make([]byte, 4 * len(signal))
+	for i, value := range signal {
+		word := uint32(value)
+		if sampleFormat == FLOAT32_FORMAT {
+			word = math.Float32bits(float32(value))
+		}
+		binary.BigEndian.PutUint32(buffer[4 * i:], word)
+	}
+	return buffer
+}
+
+
+func Write(writer io.Writer, windows []Window, options WriterOptions) error {
+	options, err := options.withDefaults()
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		return InvalidParameter{message: "Empty windows list"}
+	}
+
+	shots := make([]windowTraces, len(windows))
+	for i, window := range windows {
+		shots[i], err = readWindow(window, i)
+		if err != nil {
+			return err
+		}
+	}
+
+	frequency := shots[0].trace.Frequency
+	samplesCount := shots[0].trace.SamplesCount()
+	tracesPerShot := len(shots[0].trace.Channels)
+	for _, shot := range shots {
+		if shot.trace.Frequency != frequency || shot.trace.SamplesCount() != samplesCount {
+			return BadTraceData{message: "Windows have different sample intervals or lengths"}
+		}
+		if len(shot.trace.Channels) != tracesPerShot {
+			return BadTraceData{message: "Windows have different channels count"}
+		}
+	}
+
+	sampleInterval := int(math.Round(1e6 / float64(frequency)))
+	if sampleInterval > math.MaxUint16 || samplesCount > math.MaxUint16 {
+		return BadTraceData{message: "Sample interval or samples count does not fit binary header"}
+	}
+
+	text := formatTextualHeader(textualHeaderLines(options, shots, samplesCount, sampleInterval))
+	if options.Revision == REVISION_1 {
+		text = encodeEbcdic(text)
+	}
+	if _, err := writer.Write(text); err != nil {
+		return err
+	}
+
+	tracesCount := len(shots) * tracesPerShot
+	binaryHeader := encodeBinaryHeader(options, tracesPerShot, samplesCount, sampleInterval, tracesCount)
+	if _, err := writer.Write(binaryHeader); err != nil {
+		return err
+	}
+
+	sequenceNumber := int32(0)
+	for _, shot := range shots {
+		for i, signal := range shot.trace.Signals {
+			sequenceNumber++
+			traceHeader, err := encodeTraceHeader(TraceHeader{
+				SequenceNumber: sequenceNumber,
+				FieldRecord: shot.window.ShotNumber,
+				TraceNumber: int32(i + 1),
+				Coordinate: shot.header.Coordinate,
+				Elevation: shot.header.Gps.Altitude,
+				SampleInterval: time.Duration(sampleInterval) * time.Microsecond,
+				SamplesCount: samplesCount,
+				ShotTime: shot.window.ShotTime,
+				DatetimeStart: shot.trace.DatetimeStart})
+			if err != nil {
+				return err
+			}
+
+			if _, err := writer.Write(traceHeader); err != nil {
+				return err
+			}
+			if _, err := writer.Write(encodeSamples(signal, options.SampleFormat)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+
+func WriteFile(path string, windows []Window, options WriterOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	err = Write(writer, windows, options)
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	closeErr := file.Close()
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,23 @@
+package series
+
+import (
+	"fmt"
+)
+
+
+type InvalidParameter struct {
+	message string
+}
+
+func (customError InvalidParameter) Error() string {
+	return fmt.Sprintf("InvalidParameter: %s", customError.message)
+}
+
+
+type DataGap struct {
+	message string
+}
+
+func (customError DataGap) Error() string {
+	return fmt.Sprintf("DataGap: %s", customError.message)
+}
@@ -0,0 +1,148 @@
+package series
+
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+
+var CSV_COLUMNS = []string{
+	"type", "path", "next_path", "datetime_start", "datetime_stop",
+	"duration_seconds", "samples_count", "frequency", "next_frequency",
+}
+
+
+type segmentJSON struct {
+	Path string `json:"path"`
+	FormatType string `json:"format_type"`
+	Frequency uint16 `json:"frequency"`
+	DatetimeStart string `json:"datetime_start"`
+	DatetimeStop string `json:"datetime_stop"`
+	SamplesCount uint64 `json:"samples_count"`
+}
+
+
+type eventJSON struct {
+	Type string `json:"type"`
+	PreviousPath string `json:"previous_path"`
+	NextPath string `json:"next_path"`
+	DatetimeStart string `json:"datetime_start"`
+	DatetimeStop string `json:"datetime_stop"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	SamplesCount int64 `json:"samples_count"`
+	PreviousFrequency uint16 `json:"previous_frequency"`
+	NextFrequency uint16 `json:"next_frequency"`
+}
+
+
+type timelineJSON struct {
+	DatetimeStart string `json:"datetime_start"`
+	DatetimeStop string `json:"datetime_stop"`
+	Segments []segmentJSON `json:"segments"`
+	Events []eventJSON `json:"events"`
+}
+
+
+func formatDatetime(datetime time.Time) string {
+	if datetime.IsZero() {
+		return ""
+	}
+	return datetime.UTC().Format(time.RFC3339Nano)
+}
+
+
+func (timeline Timeline) WriteJSON(writer io.Writer) error {
+	result := timelineJSON{
+		DatetimeStart: formatDatetime(timeline.DatetimeStart()),
+		DatetimeStop: formatDatetime(timeline.DatetimeStop()),
+		Segments: []segmentJSON{},
+		Events: []eventJSON{}}
+
+	for _, segment := range timeline.Segments {
+		result.Segments = append(result.Segments, segmentJSON{
+			Path: segment.Path,
+			FormatType: segment.FormatType,
+			Frequency: segment.Frequency,
+			DatetimeStart: formatDatetime(segment.DatetimeStart),
+			DatetimeStop: formatDatetime(segment.DatetimeStop),
+			SamplesCount: segment.SamplesCount})
+	}
+
+	for _, event := range timeline.Events {
+		result.Events = append(result.Events, eventJSON{
+			Type: event.Type,
+			PreviousPath: event.PreviousPath,
+			NextPath: event.NextPath,
+			DatetimeStart: formatDatetime(event.DatetimeStart),
+			DatetimeStop: formatDatetime(event.DatetimeStop),
+			DurationSeconds: event.Duration.Seconds(),
+			SamplesCount: event.SamplesCount,
+			PreviousFrequency: event.PreviousFrequency,
+			NextFrequency: event.NextFrequency})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+
+type csvRow struct {
+	datetimeStart time.Time
+	order int
+	values []string
+}
+
+
+func (timeline Timeline) WriteCSV(writer io.Writer) error {
+	rows := []csvRow{}
+	for _, segment := range timeline.Segments {
+		rows = append(rows, csvRow{
+			datetimeStart: segment.DatetimeStart,
+			order: len(rows),
+			values: []string{
+				"segment", segment.Path, "",
+				formatDatetime(segment.DatetimeStart), formatDatetime(segment.DatetimeStop),
+				fmt.Sprint(segment.DatetimeStop.Sub(segment.DatetimeStart).Seconds()),
+				strconv.FormatUint(segment.SamplesCount, 10),
+				strconv.Itoa(int(segment.Frequency)), ""}})
+	}
+
+	for _, event := range timeline.Events {
+		rows = append(rows, csvRow{
+			datetimeStart: event.DatetimeStart,
+			order: len(rows),
+			values: []string{
+				event.Type, event.PreviousPath, event.NextPath,
+				formatDatetime(event.DatetimeStart), formatDatetime(event.DatetimeStop),
+				fmt.Sprint(event.Duration.Seconds()),
+				strconv.FormatInt(event.SamplesCount, 10),
+				strconv.Itoa(int(event.PreviousFrequency)),
+				strconv.Itoa(int(event.NextFrequency))}})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].datetimeStart.Equal(rows[j].datetimeStart) {
+			return rows[i].order < rows[j].order
+		}
+		return rows[i].datetimeStart.Before(rows[j].datetimeStart)
+	})
+
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(CSV_COLUMNS); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := csvWriter.Write(row.values); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,298 @@
+package series
+
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+const (
+	GAP_POLICY_FAIL, GAP_POLICY_ZEROS = "fail", "zeros"
+	GAP_POLICY_INTERPOLATE, GAP_POLICY_SEGMENTS = "interpolate", "segments"
+)
+
+
+type Station struct {
+	Files []binaryfile.BinaryFile
+	ResampleFrequency uint16
+	IsUseAvgValues bool
+	GapPolicy string
+}
+
+
+type piece struct {
+	index int64
+	signals [][]int32
+}
+
+func (item piece) samplesCount() int64 {
+	if len(item.signals) == 0 {
+		return 0
+	}
+	return int64(len(item.signals[0]))
+}
+
+
+func NewStation(files []binaryfile.BinaryFile) (Station, error) {
+	datetimes := make([]time.Time, len(files))
+	for i, binFile := range files {
+		recording, err := binFile.Open()
+		if err != nil {
+			return Station{}, err
+		}
+		datetimes[i] = recording.DatetimeStart()
+		recording.Close()
+	}
+
+	indexes := make([]int, len(files))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return datetimes[indexes[i]].Before(datetimes[indexes[j]])
+	})
+
+	sortedFiles := make([]binaryfile.BinaryFile, len(files))
+	for i, index := range indexes {
+		sortedFiles[i] = files[index]
+	}
+	return Station{Files: sortedFiles, GapPolicy: GAP_POLICY_FAIL}, nil
+}
+
+func (station Station) gapPolicy() (string, error) {
+	switch station.GapPolicy {
+	case "":
+		return GAP_POLICY_FAIL, nil
+	case GAP_POLICY_FAIL, GAP_POLICY_ZEROS, GAP_POLICY_INTERPOLATE, GAP_POLICY_SEGMENTS:
+		return station.GapPolicy, nil
+	default:
+		return "", InvalidParameter{message: "Unknown gap policy " + station.GapPolicy}
+	}
+}
+
+func (station Station) readPiece(recording *binaryfile.Recording, timeStart time.Time, timeStop time.Time, components []string) (binaryfile.MultichannelTrace, bool, error) {
+	recordingStart := recording.DatetimeStart()
+	recordingStop, err := recording.DatetimeStop()
+	if err != nil {
+		return binaryfile.MultichannelTrace{}, false, err
+	}
+
+	if recordingStart.After(timeStart) {
+		timeStart = recordingStart
+	}
+	if recordingStop.Before(timeStop) {
+		timeStop = recordingStop
+	}
+
+	frequency, err := recording.GetResampleFrequency()
+	if err != nil {
+		return binaryfile.MultichannelTrace{}, false, err
+	}
+
+	if timeStop.Sub(timeStart).Seconds() * float64(frequency) < 1 {
+		return binaryfile.MultichannelTrace{}, false, nil
+	}
+
+	trace, err := recording.ReadSignals(timeStart, timeStop, components)
+	if err != nil {
+		return binaryfile.MultichannelTrace{}, false, err
+	}
+	return trace, trace.SamplesCount() > 0, nil
+}
+
+func (station Station) readPieces(timeStart time.Time, timeStop time.Time, components []string) ([]piece, uint16, time.Time, error) {
+	pieces := []piece{}
+	var frequency uint16
+	var originTime time.Time
+	coveredIndex := int64(0)
+
+	for _, binFile := range station.Files {
+		recording, err := binaryfile.BinaryFile{
+			Path: binFile.Path,
+			ResampleFrequency: station.ResampleFrequency}.Open()
+		if err != nil {
+			return nil, 0, time.Time{}, err
+		}
+
+		trace, isRead, err := station.readPiece(recording, timeStart, timeStop, components)
+		recording.Close()
+		if err != nil {
+			return nil, 0, time.Time{}, err
+		}
+		if !isRead {
+			continue
+		}
+
+		if len(pieces) == 0 {
+			frequency = trace.Frequency
+			originTime = trace.DatetimeStart
+		} else if trace.Frequency != frequency {
+			message := fmt.Sprintf("Frequency changes from %d to %d Hz in %s", frequency, trace.Frequency, binFile.Path)
+			return nil, 0, time.Time{}, InvalidParameter{message: message}
+		}
+
+		index := int64(math.Round(trace.DatetimeStart.Sub(originTime).Seconds() * float64(frequency)))
+		signals := trace.Signals
+		if index < coveredIndex {
+			overlapCount := coveredIndex - index
+			if overlapCount >= int64(trace.SamplesCount()) {
+				continue
+			}
+			for i := range signals {
+				signals[i] = signals[i][overlapCount:]
+			}
+			index = coveredIndex
+		}
+
+		item := piece{index: index, signals: signals}
+		pieces = append(pieces, item)
+		coveredIndex = index + item.samplesCount()
+	}
+	return pieces, frequency, originTime, nil
+}
+
+
+func removeAverages(pieces []piece) {
+	if len(pieces) == 0 {
+		return
+	}
+
+	for channel := range pieces[0].signals {
+		var totalSum, count int64
+		for _, item := range pieces {
+			for _, value := range item.signals[channel] {
+				totalSum += int64(value)
+			}
+			count += item.samplesCount()
+		}
+		if count == 0 {
+			continue
+		}
+
+		average := int32(totalSum / count)
+		for _, item := range pieces {
+			signal := item.signals[channel]
+			for i := range signal {
+				signal[i] -= average
+			}
+		}
+	}
+}
+
+
+func fillGap(signal []int32, gapSamplesCount int64, nextValue int32, gapPolicy string) []int32 {
+	if gapPolicy == GAP_POLICY_ZEROS || len(signal) == 0 {
+		return append(signal, make([]int32, gapSamplesCount)...)
+	}
+
+	lastValue := float64(signal[len(signal) - 1])
+	step := (float64(nextValue) - lastValue) / float64(gapSamplesCount + 1)
+	for i := int64(1); i <= gapSamplesCount; i++ {
+		signal = append(signal, int32(math.Round(lastValue + step * float64(i))))
+	}
+	return signal
+}
+
+
+func samplesTime(originTime time.Time, index int64, frequency uint16) time.Time {
+	return originTime.Add(binaryfile.SamplesDuration(index, frequency))
+}
+
+func (station Station) ReadSegments(timeStart time.Time, timeStop time.Time, components []string) ([]binaryfile.MultichannelTrace, error) {
+	gapPolicy, err := station.gapPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(station.Files) == 0 {
+		return nil, InvalidParameter{message: "Station has no files"}
+	}
+
+	if len(components) == 0 {
+		recording, err := station.Files[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		components = recording.ChannelNames()
+		recording.Close()
+	}
+
+	pieces, frequency, originTime, err := station.readPieces(timeStart, timeStop, components)
+	if err != nil {
+		return nil, err
+	}
+	if len(pieces) == 0 {
+		return nil, DataGap{message: "No data in requested time interval"}
+	}
+
+	if gapPolicy == GAP_POLICY_FAIL {
+		halfSample := binaryfile.SamplesDuration(1, frequency) / 2
+		stopIndex := pieces[len(pieces) - 1].index + pieces[len(pieces) - 1].samplesCount()
+		if originTime.Sub(timeStart) > halfSample || timeStop.Sub(samplesTime(originTime, stopIndex, frequency)) > halfSample {
+			return nil, DataGap{message: "Requested time interval is not fully covered by station files"}
+		}
+	}
+
+	if station.IsUseAvgValues {
+		removeAverages(pieces)
+	}
+
+	traces := []binaryfile.MultichannelTrace{}
+	var current binaryfile.MultichannelTrace
+	coveredIndex := int64(0)
+	for i, item := range pieces {
+		gapSamplesCount := item.index - coveredIndex
+		if i == 0 || (gapSamplesCount > 0 && gapPolicy == GAP_POLICY_SEGMENTS) {
+			if i != 0 {
+				traces = append(traces, current)
+			}
+			current = binaryfile.MultichannelTrace{
+				Channels: append([]string{}, components...),
+				Signals: make([][]int32, len(components)),
+				Frequency: frequency,
+				DatetimeStart: samplesTime(originTime, item.index, frequency)}
+			gapSamplesCount = 0
+		}
+
+		if gapSamplesCount > 0 && gapPolicy == GAP_POLICY_FAIL {
+			gapStart := samplesTime(originTime, coveredIndex, frequency)
+			message := fmt.Sprintf("Gap of %d samples at %s", gapSamplesCount, gapStart.Format(time.RFC3339Nano))
+			return nil, DataGap{message: message}
+		}
+
+		for channel, signal := range item.signals {
+			if gapSamplesCount > 0 {
+				current.Signals[channel] = fillGap(current.Signals[channel], gapSamplesCount, signal[0], gapPolicy)
+			}
+			current.Signals[channel] = append(current.Signals[channel], signal...)
+		}
+		coveredIndex = item.index + item.samplesCount()
+	}
+	return append(traces, current), nil
+}
+
+func (station Station) ReadSignals(timeStart time.Time, timeStop time.Time, components []string) (binaryfile.MultichannelTrace, error) {
+	traces, err := station.ReadSegments(timeStart, timeStop, components)
+	if err != nil {
+		return binaryfile.MultichannelTrace{}, err
+	}
+
+	if len(traces) != 1 {
+		message := fmt.Sprintf("Requested time interval is split into %d segments", len(traces))
+		return binaryfile.MultichannelTrace{}, DataGap{message: message}
+	}
+	return traces[0], nil
+}
+
+func (station Station) ReadSignal(timeStart time.Time, timeStop time.Time, component rune) ([]int32, error) {
+	trace, err := station.ReadSignals(timeStart, timeStop, []string{string(component)})
+	if err != nil {
+		return []int32{}, err
+	}
+	return trace.Signals[0], nil
+}
@@ -0,0 +1,161 @@
+package series
+
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+const (
+	TEST_STATION_FREQUENCY = 100
+	TEST_FILE_SAMPLES_COUNT = 1000
+	TEST_GAP_SAMPLES_COUNT = 200
+	TEST_FIRST_VALUE, TEST_SECOND_VALUE = 100, 300
+)
+
+
+var testStationStart = time.Date(2022, 1, 20, 8, 0, 0, 0, time.UTC)
+
+
+func samplesOffset(samplesCount int) time.Time {
+	return testStationStart.Add(time.Duration(samplesCount) * time.Second / TEST_STATION_FREQUENCY)
+}
+
+
+func writeStationFile(t *testing.T, path string, start time.Time, value int32) binaryfile.BinaryFile {
+	signals := make([][]int32, 3)
+	for i := range signals {
+		signals[i] = make([]int32, TEST_FILE_SAMPLES_COUNT)
+		for j := range signals[i] {
+			signals[i][j] = value
+		}
+	}
+
+	header := binaryfile.FileHeader{
+		Frequency: TEST_STATION_FREQUENCY,
+		DatetimeStart: start,
+		Coordinate: binaryfile.Coordinate{Latitude: 55.5, Longitude: 84.25},
+		StationName: "TEST"}
+	if err := binaryfile.WriteBinaryFile(path, binaryfile.BAIKAL7_FMT, header, signals); err != nil {
+		t.Fatal(err)
+	}
+	return binaryfile.BinaryFile{Path: path}
+}
+
+
+// Two files of 10 s each with a 2 s gap between them, listed out of order.
+func gapTestStation(t *testing.T, gapPolicy string) Station {
+	directory := t.TempDir()
+	secondStart := samplesOffset(TEST_FILE_SAMPLES_COUNT + TEST_GAP_SAMPLES_COUNT)
+	station, err := NewStation([]binaryfile.BinaryFile{
+		writeStationFile(t, filepath.Join(directory, "second.00"), secondStart, TEST_SECOND_VALUE),
+		writeStationFile(t, filepath.Join(directory, "first.00"), testStationStart, TEST_FIRST_VALUE),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	station.GapPolicy = gapPolicy
+	return station
+}
+
+
+func readGapTestSegments(t *testing.T, gapPolicy string) ([]binaryfile.MultichannelTrace, error) {
+	station := gapTestStation(t, gapPolicy)
+	timeStop := samplesOffset(2 * TEST_FILE_SAMPLES_COUNT + TEST_GAP_SAMPLES_COUNT)
+	return station.ReadSegments(testStationStart, timeStop, []string{"Z"})
+}
+
+
+func checkSamples(t *testing.T, signal []int32, start int, stop int, value int32) {
+	t.Helper()
+	for i := start; i < stop; i++ {
+		if signal[i] != value {
+			t.Fatalf("Sample %d is %d, want %d", i, signal[i], value)
+		}
+	}
+}
+
+
+func TestReadSegmentsGapFail(t *testing.T) {
+	_, err := readGapTestSegments(t, GAP_POLICY_FAIL)
+	var gapError DataGap
+	if !errors.As(err, &gapError) {
+		t.Fatalf("Gap is not reported: %v", err)
+	}
+}
+
+
+func TestReadSegmentsGapZeros(t *testing.T) {
+	traces, err := readGapTestSegments(t, GAP_POLICY_ZEROS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 1 {
+		t.Fatalf("%d segments, want 1", len(traces))
+	}
+
+	signal := traces[0].Signals[0]
+	gapStop := TEST_FILE_SAMPLES_COUNT + TEST_GAP_SAMPLES_COUNT
+	if len(signal) != gapStop + TEST_FILE_SAMPLES_COUNT {
+		t.Fatalf("%d samples, want %d", len(signal), gapStop + TEST_FILE_SAMPLES_COUNT)
+	}
+	checkSamples(t, signal, 0, TEST_FILE_SAMPLES_COUNT, TEST_FIRST_VALUE)
+	checkSamples(t, signal, TEST_FILE_SAMPLES_COUNT, gapStop, 0)
+	checkSamples(t, signal, gapStop, len(signal), TEST_SECOND_VALUE)
+}
+
+
+func TestReadSegmentsGapInterpolate(t *testing.T) {
+	traces, err := readGapTestSegments(t, GAP_POLICY_INTERPOLATE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 1 {
+		t.Fatalf("%d segments, want 1", len(traces))
+	}
+
+	signal := traces[0].Signals[0]
+	gapStop := TEST_FILE_SAMPLES_COUNT + TEST_GAP_SAMPLES_COUNT
+	if len(signal) != gapStop + TEST_FILE_SAMPLES_COUNT {
+		t.Fatalf("%d samples, want %d", len(signal), gapStop + TEST_FILE_SAMPLES_COUNT)
+	}
+	checkSamples(t, signal, 0, TEST_FILE_SAMPLES_COUNT, TEST_FIRST_VALUE)
+	checkSamples(t, signal, gapStop, len(signal), TEST_SECOND_VALUE)
+
+	for i := TEST_FILE_SAMPLES_COUNT; i < gapStop; i++ {
+		if signal[i] < signal[i - 1] || signal[i] >= TEST_SECOND_VALUE {
+			t.Fatalf("Sample %d is %d after %d", i, signal[i], signal[i - 1])
+		}
+	}
+	if first, last := signal[TEST_FILE_SAMPLES_COUNT], signal[gapStop - 1]; first != 101 || last != 299 {
+		t.Errorf("Gap is filled from %d to %d, want from 101 to 299", first, last)
+	}
+}
+
+
+func TestReadSegmentsGapSegments(t *testing.T) {
+	traces, err := readGapTestSegments(t, GAP_POLICY_SEGMENTS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 2 {
+		t.Fatalf("%d segments, want 2", len(traces))
+	}
+
+	starts := []time.Time{testStationStart, samplesOffset(TEST_FILE_SAMPLES_COUNT + TEST_GAP_SAMPLES_COUNT)}
+	values := []int32{TEST_FIRST_VALUE, TEST_SECOND_VALUE}
+	for i, trace := range traces {
+		if !trace.DatetimeStart.Equal(starts[i]) {
+			t.Errorf("Segment %d starts at %s, want %s", i, trace.DatetimeStart, starts[i])
+		}
+		if trace.Frequency != TEST_STATION_FREQUENCY || trace.SamplesCount() != TEST_FILE_SAMPLES_COUNT {
+			t.Errorf("Segment %d has %d samples at %d Hz", i, trace.SamplesCount(), trace.Frequency)
+		}
+		checkSamples(t, trace.Signals[0], 0, trace.SamplesCount(), values[i])
+	}
+}
@@ -0,0 +1,203 @@
+package series
+
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+const (
+	GAP_EVENT, OVERLAP_EVENT, FREQUENCY_CHANGE_EVENT = "gap", "overlap", "frequency_change"
+)
+
+
+type Segment struct {
+	Path string
+	FormatType string
+	Frequency uint16
+	DatetimeStart time.Time
+	DatetimeStop time.Time
+	SamplesCount uint64
+}
+
+
+func NewSegment(recording *binaryfile.Recording) (Segment, error) {
+	samplesCount, err := recording.SamplesCount()
+	if err != nil {
+		return Segment{}, err
+	}
+
+	header := recording.Header()
+	if header.Frequency == 0 {
+		return Segment{}, InvalidParameter{message: "Zero frequency in " + recording.Path()}
+	}
+
+	datetimeStart := recording.DatetimeStart()
+	return Segment{
+		Path: recording.Path(),
+		FormatType: recording.FormatType(),
+		Frequency: header.Frequency,
+		DatetimeStart: datetimeStart,
+		DatetimeStop: datetimeStart.Add(binaryfile.SamplesDuration(int64(samplesCount), header.Frequency)),
+		SamplesCount: samplesCount}, nil
+}
+
+
+type Event struct {
+	Type string
+	PreviousPath string
+	NextPath string
+	DatetimeStart time.Time
+	DatetimeStop time.Time
+	Duration time.Duration
+	SamplesCount int64
+	PreviousFrequency uint16
+	NextFrequency uint16
+}
+
+
+func durationSamples(duration time.Duration, frequency uint16) int64 {
+	return int64(math.Round(duration.Seconds() * float64(frequency)))
+}
+
+
+func compareSegments(previous Segment, next Segment) []Event {
+	events := []Event{}
+	event := Event{
+		PreviousPath: previous.Path,
+		NextPath: next.Path,
+		PreviousFrequency: previous.Frequency,
+		NextFrequency: next.Frequency}
+
+	if previous.Frequency != next.Frequency {
+		frequencyChange := event
+		frequencyChange.Type = FREQUENCY_CHANGE_EVENT
+		frequencyChange.DatetimeStart = next.DatetimeStart
+		frequencyChange.DatetimeStop = next.DatetimeStart
+		events = append(events, frequencyChange)
+	}
+
+	difference := next.DatetimeStart.Sub(previous.DatetimeStop)
+	samplesCount := durationSamples(difference, previous.Frequency)
+	switch {
+	case samplesCount > 0:
+		event.Type = GAP_EVENT
+		event.DatetimeStart = previous.DatetimeStop
+		event.DatetimeStop = next.DatetimeStart
+		event.Duration = difference
+		event.SamplesCount = samplesCount
+		events = append(events, event)
+	case samplesCount < 0:
+		event.Type = OVERLAP_EVENT
+		event.DatetimeStart = next.DatetimeStart
+		event.DatetimeStop = previous.DatetimeStop
+		if next.DatetimeStop.Before(previous.DatetimeStop) {
+			event.DatetimeStop = next.DatetimeStop
+		}
+		event.Duration = event.DatetimeStop.Sub(event.DatetimeStart)
+		event.SamplesCount = durationSamples(event.Duration, previous.Frequency)
+		events = append(events, event)
+	}
+	return events
+}
+
+
+type Timeline struct {
+	Segments []Segment
+	Events []Event
+}
+
+func (timeline Timeline) eventsByType(eventType string) []Event {
+	events := []Event{}
+	for _, event := range timeline.Events {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (timeline Timeline) Gaps() []Event {
+	return timeline.eventsByType(GAP_EVENT)
+}
+
+func (timeline Timeline) Overlaps() []Event {
+	return timeline.eventsByType(OVERLAP_EVENT)
+}
+
+func (timeline Timeline) FrequencyChanges() []Event {
+	return timeline.eventsByType(FREQUENCY_CHANGE_EVENT)
+}
+
+func (timeline Timeline) IsContinuous() bool {
+	return len(timeline.Events) == 0
+}
+
+func (timeline Timeline) DatetimeStart() time.Time {
+	if len(timeline.Segments) == 0 {
+		return time.Time{}
+	}
+	return timeline.Segments[0].DatetimeStart
+}
+
+func (timeline Timeline) DatetimeStop() time.Time {
+	datetimeStop := time.Time{}
+	for _, segment := range timeline.Segments {
+		if segment.DatetimeStop.After(datetimeStop) {
+			datetimeStop = segment.DatetimeStop
+		}
+	}
+	return datetimeStop
+}
+
+func (timeline Timeline) GapsDuration() time.Duration {
+	var duration time.Duration
+	for _, gap := range timeline.Gaps() {
+		duration += gap.Duration
+	}
+	return duration
+}
+
+
+func NewTimelineFromSegments(segments []Segment) Timeline {
+	sortedSegments := append([]Segment{}, segments...)
+	sort.SliceStable(sortedSegments, func(i, j int) bool {
+		if sortedSegments[i].DatetimeStart.Equal(sortedSegments[j].DatetimeStart) {
+			return sortedSegments[i].Path < sortedSegments[j].Path
+		}
+		return sortedSegments[i].DatetimeStart.Before(sortedSegments[j].DatetimeStart)
+	})
+
+	events := []Event{}
+	latestIndex := 0
+	for i := 1; i < len(sortedSegments); i++ {
+		events = append(events, compareSegments(sortedSegments[latestIndex], sortedSegments[i])...)
+		if sortedSegments[i].DatetimeStop.After(sortedSegments[latestIndex].DatetimeStop) {
+			latestIndex = i
+		}
+	}
+	return Timeline{Segments: sortedSegments, Events: events}
+}
+
+
+func NewTimeline(files []binaryfile.BinaryFile) (Timeline, error) {
+	segments := make([]Segment, 0, len(files))
+	for _, binFile := range files {
+		recording, err := binFile.Open()
+		if err != nil {
+			return Timeline{}, err
+		}
+
+		segment, err := NewSegment(recording)
+		recording.Close()
+		if err != nil {
+			return Timeline{}, err
+		}
+		segments = append(segments, segment)
+	}
+	return NewTimelineFromSegments(segments), nil
+}
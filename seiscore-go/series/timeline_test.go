@@ -0,0 +1,60 @@
+package series
+
+
+import (
+	"testing"
+	"time"
+)
+
+
+func testSegment(path string, start time.Time, duration time.Duration) Segment {
+	return Segment{
+		Path: path,
+		Frequency: 100,
+		DatetimeStart: start,
+		DatetimeStop: start.Add(duration),
+		SamplesCount: uint64(duration.Seconds() * 100)}
+}
+
+
+func TestTimelineNestedSegment(t *testing.T) {
+	start := time.Date(2022, 1, 20, 0, 0, 0, 0, time.UTC)
+	timeline := NewTimelineFromSegments([]Segment{
+		testSegment("outer", start, time.Hour),
+		testSegment("inner", start.Add(10 * time.Minute), 10 * time.Minute),
+		testSegment("next", start.Add(time.Hour), time.Hour),
+	})
+
+	if gaps := timeline.Gaps(); len(gaps) != 0 {
+		t.Errorf("Nested segment produces gaps %+v", gaps)
+	}
+
+	overlaps := timeline.Overlaps()
+	if len(overlaps) != 1 {
+		t.Fatalf("Overlaps count %d, want 1", len(overlaps))
+	}
+	if overlaps[0].PreviousPath != "outer" || overlaps[0].NextPath != "inner" {
+		t.Errorf("Overlap between %s and %s", overlaps[0].PreviousPath, overlaps[0].NextPath)
+	}
+	if overlaps[0].Duration != 10 * time.Minute {
+		t.Errorf("Overlap duration %s, want 10m", overlaps[0].Duration)
+	}
+}
+
+
+func TestTimelineGapAfterNestedSegment(t *testing.T) {
+	start := time.Date(2022, 1, 20, 0, 0, 0, 0, time.UTC)
+	timeline := NewTimelineFromSegments([]Segment{
+		testSegment("outer", start, time.Hour),
+		testSegment("inner", start.Add(10 * time.Minute), 10 * time.Minute),
+		testSegment("next", start.Add(70 * time.Minute), time.Hour),
+	})
+
+	gaps := timeline.Gaps()
+	if len(gaps) != 1 {
+		t.Fatalf("Gaps count %d, want 1", len(gaps))
+	}
+	if gaps[0].PreviousPath != "outer" || gaps[0].Duration != 10 * time.Minute {
+		t.Errorf("Gap after %s of %s, want after outer of 10m", gaps[0].PreviousPath, gaps[0].Duration)
+	}
+}
@@ -0,0 +1,445 @@
+package tools
+
+
+import (
+	"math"
+	"math/cmplx"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+const (
+	GEOMETRIC_MEAN, QUADRATIC_MEAN, VECTOR_SUM = "geometric_mean", "quadratic_mean", "vector_sum"
+	HVSR_WINDOW_SECONDS = 25
+	HVSR_TAPER_ALPHA = 0.05
+	HVSR_STA_SECONDS, HVSR_LTA_SECONDS = 1, 30
+	HVSR_STA_LTA_MIN, HVSR_STA_LTA_MAX = 0.2, 2.5
+	HVSR_SMOOTHING_BANDWIDTH = 40
+	SESAME_MIN_CYCLES_COUNT = 200
+	SESAME_PEAK_STABILITY = 0.05
+)
+
+
+type HVSRParameters struct {
+	WindowSeconds float64
+	Overlap float64
+	TaperAlpha float64
+	StaSeconds float64
+	LtaSeconds float64
+	StaLtaMin float64
+	StaLtaMax float64
+	SmoothingBandwidth float64
+	Combination string
+	FrequencyMin float64
+	FrequencyMax float64
+}
+
+func (parameters HVSRParameters) withDefaults(samplingRate float64) HVSRParameters {
+	if parameters.WindowSeconds == 0 {
+		parameters.WindowSeconds = HVSR_WINDOW_SECONDS
+	}
+	if parameters.TaperAlpha == 0 {
+		parameters.TaperAlpha = HVSR_TAPER_ALPHA
+	}
+	if parameters.StaSeconds == 0 {
+		parameters.StaSeconds = HVSR_STA_SECONDS
+	}
+	if parameters.LtaSeconds == 0 {
+		parameters.LtaSeconds = HVSR_LTA_SECONDS
+	}
+	if parameters.StaLtaMin == 0 {
+		parameters.StaLtaMin = HVSR_STA_LTA_MIN
+	}
+	if parameters.StaLtaMax == 0 {
+		parameters.StaLtaMax = HVSR_STA_LTA_MAX
+	}
+	if parameters.SmoothingBandwidth == 0 {
+		parameters.SmoothingBandwidth = HVSR_SMOOTHING_BANDWIDTH
+	}
+	if len(parameters.Combination) == 0 {
+		parameters.Combination = GEOMETRIC_MEAN
+	}
+	if parameters.FrequencyMin == 0 {
+		parameters.FrequencyMin = 1 / parameters.WindowSeconds
+	}
+	if parameters.FrequencyMax == 0 {
+		parameters.FrequencyMax = samplingRate / 2
+	}
+	return parameters
+}
+
+func (parameters HVSRParameters) check() error {
+	if parameters.Overlap < 0 || parameters.Overlap >= 1 {
+		return InvalidParameter{"Overlap must be in range [0, 1)"}
+	}
+	if parameters.StaSeconds >= parameters.LtaSeconds {
+		return InvalidParameter{"STA length must be less than LTA length"}
+	}
+	if parameters.StaLtaMin >= parameters.StaLtaMax {
+		return InvalidParameter{"Invalid STA/LTA range"}
+	}
+	if parameters.FrequencyMin <= 0 || parameters.FrequencyMax <= parameters.FrequencyMin {
+		return InvalidParameter{"Invalid frequency range"}
+	}
+	switch parameters.Combination {
+	case GEOMETRIC_MEAN, QUADRATIC_MEAN, VECTOR_SUM:
+		return nil
+	default:
+		return InvalidParameter{"Unknown horizontal combination " + parameters.Combination}
+	}
+}
+
+
+func combineHorizontals(first float64, second float64, combination string) float64 {
+	switch combination {
+	case QUADRATIC_MEAN:
+		return math.Sqrt((first * first + second * second) / 2)
+	case VECTOR_SUM:
+		return math.Sqrt(first * first + second * second)
+	default:
+		return math.Sqrt(first * second)
+	}
+}
+
+
+func staLtaRatios(signal []float64, staLength int, ltaLength int) []float64 {
+	mean := 0.0
+	for _, value := range signal {
+		mean += value
+	}
+	mean /= float64(len(signal))
+
+	cumulative := make([]float64, len(signal) + 1)
+	for i, value := range signal {
+		cumulative[i + 1] = cumulative[i] + math.Abs(value - mean)
+	}
+
+	ratios := make([]float64, len(signal))
+	for i := range ratios {
+		if i + 1 < ltaLength {
+			ratios[i] = math.NaN()
+			continue
+		}
+
+		sta := (cumulative[i + 1] - cumulative[i + 1 - staLength]) / float64(staLength)
+		lta := (cumulative[i + 1] - cumulative[i + 1 - ltaLength]) / float64(ltaLength)
+		if lta == 0 {
+			ratios[i] = math.NaN()
+			continue
+		}
+		ratios[i] = sta / lta
+	}
+	return ratios
+}
+
+
+func isQuietWindow(ratios [][]float64, start int, stop int, parameters HVSRParameters) bool {
+	for _, componentRatios := range ratios {
+		for _, ratio := range componentRatios[start:stop] {
+			if math.IsNaN(ratio) {
+				continue
+			}
+			if ratio < parameters.StaLtaMin || ratio > parameters.StaLtaMax {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+
+func amplitudeSpectrum(signal []float64, weights []float64) []float64 {
+	coefficients := windowedCoefficients(detrend(signal), weights, len(signal))
+	amplitudes := make([]float64, len(coefficients))
+	for i, coefficient := range coefficients {
+		amplitudes[i] = cmplx.Abs(coefficient)
+	}
+	return amplitudes
+}
+
+
+type SesameCriteria struct {
+	IsWindowLengthOk bool
+	IsCyclesCountOk bool
+	IsAmplitudeStdOk bool
+	IsLowerHalfAmplitudeFound bool
+	IsUpperHalfAmplitudeFound bool
+	IsPeakAmplitudeOk bool
+	IsPeakStable bool
+	IsFrequencyStdOk bool
+	IsPeakAmplitudeStdOk bool
+}
+
+func (criteria SesameCriteria) IsReliable() bool {
+	return criteria.IsWindowLengthOk && criteria.IsCyclesCountOk && criteria.IsAmplitudeStdOk
+}
+
+func (criteria SesameCriteria) ClearPeakPassedCount() int {
+	count := 0
+	for _, isPassed := range []bool{
+		criteria.IsLowerHalfAmplitudeFound, criteria.IsUpperHalfAmplitudeFound, criteria.IsPeakAmplitudeOk,
+		criteria.IsPeakStable, criteria.IsFrequencyStdOk, criteria.IsPeakAmplitudeStdOk} {
+		if isPassed {
+			count++
+		}
+	}
+	return count
+}
+
+func (criteria SesameCriteria) IsClearPeak() bool {
+	return criteria.ClearPeakPassedCount() >= 5
+}
+
+
+func sesameThresholds(frequency float64) (float64, float64) {
+	switch {
+	case frequency < 0.2:
+		return 0.25 * frequency, 3
+	case frequency < 0.5:
+		return 0.2 * frequency, 2.5
+	case frequency < 1:
+		return 0.15 * frequency, 2
+	case frequency < 2:
+		return 0.1 * frequency, 1.78
+	default:
+		return 0.05 * frequency, 1.58
+	}
+}
+
+
+type HVSR struct {
+	Frequencies []float64
+	Curves [][]float64
+	Mean []float64
+	StdDev []float64
+	WindowSeconds float64
+	WindowsCount int
+	RejectedCount int
+	PeakFrequency float64
+	PeakAmplitude float64
+	PeakFrequencyStdDev float64
+	Criteria SesameCriteria
+}
+
+func (hvsr HVSR) Curve() [][]float64 {
+	return pairs(hvsr.Frequencies, hvsr.Mean)
+}
+
+func (hvsr HVSR) StdDevCurve() [][]float64 {
+	return pairs(hvsr.Frequencies, hvsr.StdDev)
+}
+
+func (hvsr HVSR) LowerCurve() [][]float64 {
+	values := make([]float64, len(hvsr.Mean))
+	for i := range values {
+		values[i] = hvsr.Mean[i] / hvsr.StdDev[i]
+	}
+	return pairs(hvsr.Frequencies, values)
+}
+
+func (hvsr HVSR) UpperCurve() [][]float64 {
+	values := make([]float64, len(hvsr.Mean))
+	for i := range values {
+		values[i] = hvsr.Mean[i] * hvsr.StdDev[i]
+	}
+	return pairs(hvsr.Frequencies, values)
+}
+
+func (hvsr *HVSR) computeStatistics() {
+	hvsr.Mean = make([]float64, len(hvsr.Frequencies))
+	hvsr.StdDev = make([]float64, len(hvsr.Frequencies))
+	count := float64(len(hvsr.Curves))
+	for i := range hvsr.Frequencies {
+		var sum, squaresSum float64
+		for _, curve := range hvsr.Curves {
+			value := math.Log(curve[i])
+			sum += value
+			squaresSum += value * value
+		}
+
+		mean := sum / count
+		variance := 0.0
+		if count > 1 {
+			variance = math.Max(0, (squaresSum - sum * mean) / (count - 1))
+		}
+		hvsr.Mean[i] = math.Exp(mean)
+		hvsr.StdDev[i] = math.Exp(math.Sqrt(variance))
+	}
+}
+
+func peakIndex(values []float64) int {
+	index := 0
+	for i, value := range values {
+		if value > values[index] {
+			index = i
+		}
+	}
+	return index
+}
+
+func (hvsr *HVSR) computePeak() {
+	index := peakIndex(hvsr.Mean)
+	hvsr.PeakFrequency = hvsr.Frequencies[index]
+	hvsr.PeakAmplitude = hvsr.Mean[index]
+
+	var sum, squaresSum float64
+	for _, curve := range hvsr.Curves {
+		frequency := hvsr.Frequencies[peakIndex(curve)]
+		sum += frequency
+		squaresSum += frequency * frequency
+	}
+
+	count := float64(len(hvsr.Curves))
+	if count > 1 {
+		hvsr.PeakFrequencyStdDev = math.Sqrt(math.Max(0, (squaresSum - sum * sum / count) / (count - 1)))
+	}
+}
+
+func (hvsr *HVSR) computeCriteria() {
+	f0, a0 := hvsr.PeakFrequency, hvsr.PeakAmplitude
+	criteria := SesameCriteria{
+		IsWindowLengthOk: f0 > 10 / hvsr.WindowSeconds,
+		IsCyclesCountOk: hvsr.WindowSeconds * float64(len(hvsr.Curves)) * f0 > SESAME_MIN_CYCLES_COUNT,
+		IsAmplitudeStdOk: true,
+		IsPeakAmplitudeOk: a0 > 2}
+
+	amplitudeStdLimit := 2.0
+	if f0 < 0.5 {
+		amplitudeStdLimit = 3
+	}
+
+	lower, upper := make([]float64, len(hvsr.Mean)), make([]float64, len(hvsr.Mean))
+	for i, frequency := range hvsr.Frequencies {
+		lower[i] = hvsr.Mean[i] / hvsr.StdDev[i]
+		upper[i] = hvsr.Mean[i] * hvsr.StdDev[i]
+
+		if frequency > f0 / 2 && frequency < 2 * f0 && hvsr.StdDev[i] >= amplitudeStdLimit {
+			criteria.IsAmplitudeStdOk = false
+		}
+		if frequency >= f0 / 4 && frequency < f0 && hvsr.Mean[i] < a0 / 2 {
+			criteria.IsLowerHalfAmplitudeFound = true
+		}
+		if frequency > f0 && frequency <= 4 * f0 && hvsr.Mean[i] < a0 / 2 {
+			criteria.IsUpperHalfAmplitudeFound = true
+		}
+	}
+
+	lowerPeak, upperPeak := hvsr.Frequencies[peakIndex(lower)], hvsr.Frequencies[peakIndex(upper)]
+	criteria.IsPeakStable = math.Abs(lowerPeak - f0) <= SESAME_PEAK_STABILITY * f0 &&
+		math.Abs(upperPeak - f0) <= SESAME_PEAK_STABILITY * f0
+
+	frequencyThreshold, amplitudeThreshold := sesameThresholds(f0)
+	criteria.IsFrequencyStdOk = hvsr.PeakFrequencyStdDev < frequencyThreshold
+	criteria.IsPeakAmplitudeStdOk = hvsr.StdDev[peakIndex(hvsr.Mean)] < amplitudeThreshold
+	hvsr.Criteria = criteria
+}
+
+
+func ComputeHVSR(vertical []float64, firstHorizontal []float64, secondHorizontal []float64, samplingRate float64, parameters HVSRParameters) (HVSR, error) {
+	if samplingRate <= 0 {
+		return HVSR{}, InvalidParameter{"Sampling rate must be positive"}
+	}
+
+	parameters = parameters.withDefaults(samplingRate)
+	if err := parameters.check(); err != nil {
+		return HVSR{}, err
+	}
+
+	if len(firstHorizontal) != len(vertical) || len(secondHorizontal) != len(vertical) {
+		return HVSR{}, BadSignalData{"Components have different lengths"}
+	}
+
+	windowLength := int(math.Round(parameters.WindowSeconds * samplingRate))
+	if windowLength < 2 || len(vertical) < windowLength {
+		return HVSR{}, BadSignalData{"Short signal - length less than HVSR window"}
+	}
+
+	weights, err := WindowWeights(TUKEY_WINDOW, parameters.TaperAlpha, windowLength)
+	if err != nil {
+		return HVSR{}, err
+	}
+
+	staLength := int(math.Max(1, math.Round(parameters.StaSeconds * samplingRate)))
+	ltaLength := int(math.Max(2, math.Round(parameters.LtaSeconds * samplingRate)))
+	components := [][]float64{vertical, firstHorizontal, secondHorizontal}
+	ratios := make([][]float64, len(components))
+	for i, signal := range components {
+		ratios[i] = staLtaRatios(signal, staLength, ltaLength)
+	}
+
+	allFrequencies := make([]float64, windowLength / 2 + 1)
+	for i := range allFrequencies {
+		allFrequencies[i] = float64(i) * samplingRate / float64(windowLength)
+	}
+
+	hvsr := HVSR{WindowSeconds: parameters.WindowSeconds}
+	for _, frequency := range allFrequencies {
+		if frequency >= parameters.FrequencyMin && frequency <= parameters.FrequencyMax {
+			hvsr.Frequencies = append(hvsr.Frequencies, frequency)
+		}
+	}
+	if len(hvsr.Frequencies) == 0 {
+		return HVSR{}, InvalidParameter{"No spectrum frequencies inside frequency range"}
+	}
+
+	kernel, err := NewKonnoOhmachiKernel(allFrequencies, hvsr.Frequencies, parameters.SmoothingBandwidth)
+	if err != nil {
+		return HVSR{}, err
+	}
+
+	step := int(math.Max(1, math.Round(float64(windowLength) * (1 - parameters.Overlap))))
+	for start := 0; start + windowLength <= len(vertical); start += step {
+		if !isQuietWindow(ratios, start, start + windowLength, parameters) {
+			hvsr.RejectedCount++
+			continue
+		}
+
+		spectra := make([][]float64, len(components))
+		for i, signal := range components {
+			spectra[i], err = kernel.Apply(amplitudeSpectrum(signal[start:start + windowLength], weights))
+			if err != nil {
+				return HVSR{}, err
+			}
+		}
+
+		curve := make([]float64, len(hvsr.Frequencies))
+		for i := range hvsr.Frequencies {
+			horizontal := combineHorizontals(spectra[1][i], spectra[2][i], parameters.Combination)
+			curve[i] = horizontal / spectra[0][i]
+			if math.IsNaN(curve[i]) || math.IsInf(curve[i], 0) || curve[i] <= 0 {
+				curve = nil
+				break
+			}
+		}
+
+		if curve == nil {
+			hvsr.RejectedCount++
+			continue
+		}
+		hvsr.Curves = append(hvsr.Curves, curve)
+	}
+
+	hvsr.WindowsCount = len(hvsr.Curves)
+	if hvsr.WindowsCount == 0 {
+		return HVSR{}, BadSignalData{"All HVSR windows were rejected"}
+	}
+
+	hvsr.computeStatistics()
+	hvsr.computePeak()
+	hvsr.computeCriteria()
+	return hvsr, nil
+}
+
+
+func ComputeTraceHVSR(trace binaryfile.MultichannelTrace, parameters HVSRParameters) (HVSR, error) {
+	components := make([][]float64, len(binaryfile.COMPONENTS_ORDER))
+	for i, component := range binaryfile.COMPONENTS_ORDER {
+		signal, err := trace.Signal(string(component))
+		if err != nil {
+			return HVSR{}, err
+		}
+		components[i] = ToFloat(signal)
+	}
+	return ComputeHVSR(components[0], components[1], components[2], float64(trace.Frequency), parameters)
+}
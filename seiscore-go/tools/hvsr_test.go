@@ -0,0 +1,131 @@
+package tools
+
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+
+const (
+	TEST_HVSR_SAMPLING_RATE = 50
+	TEST_HVSR_PEAK_FREQUENCY = 2
+)
+
+
+var TEST_HVSR_PARAMETERS = HVSRParameters{FrequencyMin: 0.5, FrequencyMax: 10}
+
+
+func resonantNoise(random *rand.Rand, samplesCount int, frequency float64, samplingRate float64) []float64 {
+	radius, angle := 0.99, 2 * math.Pi * frequency / samplingRate
+	noise := make([]float64, samplesCount + 2)
+	for i := range noise {
+		noise[i] = random.NormFloat64()
+	}
+
+	signal := make([]float64, samplesCount)
+	var previous, beforePrevious float64
+	for i := range signal {
+		signal[i] = noise[i + 2] - noise[i] + 2 * radius * math.Cos(angle) * previous - radius * radius * beforePrevious
+		previous, beforePrevious = signal[i], previous
+	}
+	return signal
+}
+
+
+func syntheticHVSRComponents(samplesCount int, resonanceScale float64) ([]float64, []float64, []float64) {
+	random := rand.New(rand.NewSource(3))
+	components := make([][]float64, 3)
+	for i := range components {
+		components[i] = make([]float64, samplesCount)
+		for j := range components[i] {
+			components[i][j] = random.NormFloat64()
+		}
+	}
+
+	for _, horizontal := range components[1:] {
+		resonance := resonantNoise(random, samplesCount, TEST_HVSR_PEAK_FREQUENCY, TEST_HVSR_SAMPLING_RATE)
+		for i := range horizontal {
+			horizontal[i] += resonanceScale * resonance[i]
+		}
+	}
+	return components[0], components[1], components[2]
+}
+
+
+func TestHVSRSyntheticPeak(t *testing.T) {
+	vertical, first, second := syntheticHVSRComponents(600 * TEST_HVSR_SAMPLING_RATE, 0.08)
+	hvsr, err := ComputeHVSR(vertical, first, second, TEST_HVSR_SAMPLING_RATE, TEST_HVSR_PARAMETERS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hvsr.WindowsCount != 24 || hvsr.RejectedCount != 0 {
+		t.Errorf("%d windows, %d rejected, want 24 and 0", hvsr.WindowsCount, hvsr.RejectedCount)
+	}
+	if math.Abs(hvsr.PeakFrequency - TEST_HVSR_PEAK_FREQUENCY) > SESAME_PEAK_STABILITY * TEST_HVSR_PEAK_FREQUENCY {
+		t.Errorf("Peak frequency %g, want %d", hvsr.PeakFrequency, TEST_HVSR_PEAK_FREQUENCY)
+	}
+	if hvsr.PeakAmplitude < 3 {
+		t.Errorf("Peak amplitude %g is too low", hvsr.PeakAmplitude)
+	}
+	if !hvsr.Criteria.IsReliable() {
+		t.Errorf("Curve is not reliable: %+v", hvsr.Criteria)
+	}
+	if count := hvsr.Criteria.ClearPeakPassedCount(); count != 6 || !hvsr.Criteria.IsClearPeak() {
+		t.Errorf("%d clear peak criteria passed, want 6: %+v", count, hvsr.Criteria)
+	}
+}
+
+
+func TestHVSRStaLtaRejection(t *testing.T) {
+	vertical, first, second := syntheticHVSRComponents(600 * TEST_HVSR_SAMPLING_RATE, 0.08)
+	burstStart := 310 * TEST_HVSR_SAMPLING_RATE
+	for i := burstStart; i < burstStart + TEST_HVSR_SAMPLING_RATE / 2; i++ {
+		vertical[i] *= 50
+	}
+
+	hvsr, err := ComputeHVSR(vertical, first, second, TEST_HVSR_SAMPLING_RATE, TEST_HVSR_PARAMETERS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hvsr.WindowsCount != 23 || hvsr.RejectedCount != 1 {
+		t.Errorf("%d windows, %d rejected, want 23 and 1", hvsr.WindowsCount, hvsr.RejectedCount)
+	}
+	if math.Abs(hvsr.PeakFrequency - TEST_HVSR_PEAK_FREQUENCY) > SESAME_PEAK_STABILITY * TEST_HVSR_PEAK_FREQUENCY {
+		t.Errorf("Peak frequency %g, want %d", hvsr.PeakFrequency, TEST_HVSR_PEAK_FREQUENCY)
+	}
+}
+
+
+func TestHVSRWithoutPeak(t *testing.T) {
+	vertical, first, second := syntheticHVSRComponents(600 * TEST_HVSR_SAMPLING_RATE, 0)
+	hvsr, err := ComputeHVSR(vertical, first, second, TEST_HVSR_SAMPLING_RATE, TEST_HVSR_PARAMETERS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hvsr.Criteria.IsPeakAmplitudeOk || hvsr.Criteria.IsClearPeak() {
+		t.Errorf("Flat ratio has a clear peak: %+v", hvsr.Criteria)
+	}
+}
+
+
+func TestHVSRParametersCheck(t *testing.T) {
+	vertical, first, second := syntheticHVSRComponents(100 * TEST_HVSR_SAMPLING_RATE, 0)
+	cases := []HVSRParameters{
+		{Overlap: 1},
+		{StaSeconds: 30, LtaSeconds: 10},
+		{StaLtaMin: 3, StaLtaMax: 2},
+		{FrequencyMin: 5, FrequencyMax: 1},
+		{Combination: "maximum"},
+	}
+	for _, parameters := range cases {
+		if _, err := ComputeHVSR(vertical, first, second, TEST_HVSR_SAMPLING_RATE, parameters); err == nil {
+			t.Errorf("Parameters %+v are accepted", parameters)
+		}
+	}
+	if _, err := ComputeHVSR(vertical, first[1:], second, TEST_HVSR_SAMPLING_RATE, HVSRParameters{}); err == nil {
+		t.Error("Components of different lengths are accepted")
+	}
+}
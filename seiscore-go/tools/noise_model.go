@@ -0,0 +1,141 @@
+package tools
+
+
+import (
+	"math"
+	"sort"
+)
+
+
+type NoiseModel struct {
+	Periods []float64
+	A []float64
+	B []float64
+}
+
+func (model NoiseModel) MinPeriod() float64 {
+	return model.Periods[0]
+}
+
+func (model NoiseModel) MaxPeriod() float64 {
+	return model.Periods[len(model.Periods) - 1]
+}
+
+func (model NoiseModel) Value(period float64) float64 {
+	if period < model.MinPeriod() || period > model.MaxPeriod() {
+		return math.NaN()
+	}
+
+	index := sort.SearchFloat64s(model.Periods, period)
+	if index > 0 && (index == len(model.Periods) - 1 || model.Periods[index] > period) {
+		index--
+	}
+	return model.A[index] + model.B[index] * math.Log10(period)
+}
+
+func (model NoiseModel) Curve(periods []float64) [][]float64 {
+	result := [][]float64{}
+	for _, period := range periods {
+		value := model.Value(period)
+		if math.IsNaN(value) {
+			continue
+		}
+		result = append(result, []float64{period, value})
+	}
+	return result
+}
+
+
+var NLNM = NoiseModel{
+	Periods: []float64{
+		0.1, 0.17, 0.4, 0.8, 1.24, 2.4, 4.3, 5, 6, 10, 12, 15.6, 21.9, 31.6, 45, 70,
+		101, 154, 328, 600, 10000, 100000,
+	},
+	A: []float64{
+		-162.36, -166.7, -170, -166.4, -168.6, -159.98, -141.1, -71.36, -97.26, -132.18, -205.27,
+		-37.65, -114.37, -160.58, -187.5, -216.47, -185, -168.34, -217.43, -258.28, -346.88,
+	},
+	B: []float64{
+		5.64, 0, -8.3, 28.9, 52.48, 29.81, 0, -99.77, -66.49, -31.57, 36.16,
+		-104.33, -47.1, -16.28, 0, 15.7, 0, -7.61, 11.9, 26.6, 48.75,
+	},
+}
+
+
+var NHNM = NoiseModel{
+	Periods: []float64{0.1, 0.22, 0.32, 0.8, 3.8, 4.6, 6.3, 7.9, 15.4, 20, 354.8, 100000},
+	A: []float64{
+		-108.73, -150.34, -122.31, -116.85, -108.48, -74.66, 0.66, -93.37, 73.54, -151.52, -206.66,
+	},
+	B: []float64{
+		-17.23, -80.5, -23.87, 32.51, 18.08, -32.95, -127.18, -22.42, -162.98, 10.01, 31.63,
+	},
+}
+
+
+type NoiseLevel struct {
+	PointsCount int
+	MeanAboveNLNM float64
+	MeanBelowNHNM float64
+	MeanRelativeLevel float64
+	AboveNHNMRatio float64
+	BelowNLNMRatio float64
+}
+
+
+func CompareNoiseModels(psd PSD, periodLimit Limit) (NoiseLevel, error) {
+	level := NoiseLevel{}
+	var aboveNHNMCount, belowNLNMCount int
+	for _, point := range psd.Periods() {
+		period, value := point[0], point[1]
+		if periodLimit.Low != 0 && period < periodLimit.Low {
+			continue
+		}
+		if periodLimit.High != 0 && period > periodLimit.High {
+			continue
+		}
+
+		low, high := NLNM.Value(period), NHNM.Value(period)
+		if math.IsNaN(low) || math.IsNaN(high) || math.IsInf(value, 0) {
+			continue
+		}
+
+		level.PointsCount++
+		level.MeanAboveNLNM += value - low
+		level.MeanBelowNHNM += high - value
+		level.MeanRelativeLevel += (value - low) / (high - low)
+		if value > high {
+			aboveNHNMCount++
+		}
+		if value < low {
+			belowNLNMCount++
+		}
+	}
+
+	if level.PointsCount == 0 {
+		return NoiseLevel{}, BadSignalData{"No PSD values inside noise models period range"}
+	}
+
+	count := float64(level.PointsCount)
+	level.MeanAboveNLNM /= count
+	level.MeanBelowNHNM /= count
+	level.MeanRelativeLevel /= count
+	level.AboveNHNMRatio = float64(aboveNHNMCount) / count
+	level.BelowNLNMRatio = float64(belowNLNMCount) / count
+	return level, nil
+}
+
+
+type StationNoise struct {
+	Name string
+	Level NoiseLevel
+}
+
+
+func RankNoiseLevels(stations []StationNoise) []StationNoise {
+	ranked := append([]StationNoise{}, stations...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Level.MeanAboveNLNM < ranked[j].Level.MeanAboveNLNM
+	})
+	return ranked
+}
@@ -0,0 +1,55 @@
+package tools
+
+
+import (
+	"math"
+	"testing"
+)
+
+
+func TestNoiseModelBreakpoints(t *testing.T) {
+	for name, model := range map[string]NoiseModel{"NLNM": NLNM, "NHNM": NHNM} {
+		if len(model.A) != len(model.Periods) - 1 || len(model.B) != len(model.A) {
+			t.Fatalf("%s has %d periods, %d A and %d B coefficients", name, len(model.Periods), len(model.A), len(model.B))
+		}
+
+		for i, period := range model.Periods {
+			index := i
+			if index == len(model.A) {
+				index--
+			}
+			expected := model.A[index] + model.B[index] * math.Log10(period)
+			if value := model.Value(period); value != expected {
+				t.Errorf("%s at %g s is %g, want %g", name, period, value, expected)
+			}
+
+			if i > 0 && i < len(model.A) {
+				previous := model.A[i - 1] + model.B[i - 1] * math.Log10(period)
+				if math.Abs(previous - expected) > 0.02 {
+					t.Errorf("%s is discontinuous at %g s: %g and %g", name, period, previous, expected)
+				}
+			}
+		}
+
+		if !math.IsNaN(model.Value(model.MinPeriod() / 2)) || !math.IsNaN(model.Value(model.MaxPeriod() * 2)) {
+			t.Errorf("%s is defined outside its period range", name)
+		}
+	}
+
+	knownValues := []struct {
+		model NoiseModel
+		period float64
+		expected float64
+	}{
+		{NLNM, 0.1, -168},
+		{NLNM, 10, -163.75},
+		{NLNM, 100000, -103.13},
+		{NHNM, 0.1, -91.5},
+		{NHNM, 100000, -48.51},
+	}
+	for _, value := range knownValues {
+		if result := value.model.Value(value.period); math.Abs(result - value.expected) > 1e-9 {
+			t.Errorf("Model value at %g s is %g, want %g", value.period, result, value.expected)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+package tools
+
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"example.com/seiscore-go/binaryfile"
+)
+
+
+const (
+	PPSD_WINDOW_SECONDS = 3600
+	PPSD_WINDOW_OVERLAP = 0.5
+	PPSD_DEFAULT_WINDOW_OVERLAP = -1
+	PPSD_SEGMENTS_RATIO = 4
+	PPSD_SEGMENT_OVERLAP = 0.75
+	PPSD_DECIBEL_MIN, PPSD_DECIBEL_MAX, PPSD_DECIBEL_STEP = -200, -50, 1
+	PPSD_PERIOD_STEP_OCTAVES, PPSD_PERIOD_BAND_OCTAVES = 0.125, 1
+)
+
+
+type PPSDParameters struct {
+	SamplingRate float64
+	WindowSeconds float64
+	// Fraction in [0, 1). Zero means no overlap, PPSD_DEFAULT_WINDOW_OVERLAP (-1) selects PPSD_WINDOW_OVERLAP.
+	WindowOverlap float64
+	CountsPerUnit float64
+	IsVelocity bool
+	PeriodMin float64
+	PeriodMax float64
+	DecibelMin float64
+	DecibelMax float64
+	DecibelStep float64
+}
+
+func (parameters PPSDParameters) withDefaults() PPSDParameters {
+	if parameters.WindowSeconds == 0 {
+		parameters.WindowSeconds = PPSD_WINDOW_SECONDS
+	}
+	if parameters.WindowOverlap == PPSD_DEFAULT_WINDOW_OVERLAP {
+		parameters.WindowOverlap = PPSD_WINDOW_OVERLAP
+	}
+	if parameters.PeriodMin == 0 && parameters.SamplingRate > 0 {
+		parameters.PeriodMin = 2 / parameters.SamplingRate
+	}
+	if parameters.PeriodMax == 0 {
+		parameters.PeriodMax = parameters.WindowSeconds / PPSD_SEGMENTS_RATIO
+	}
+	if parameters.DecibelMin == 0 && parameters.DecibelMax == 0 {
+		parameters.DecibelMin, parameters.DecibelMax = PPSD_DECIBEL_MIN, PPSD_DECIBEL_MAX
+	}
+	if parameters.DecibelStep == 0 {
+		parameters.DecibelStep = PPSD_DECIBEL_STEP
+	}
+	return parameters
+}
+
+func (parameters PPSDParameters) check() error {
+	if parameters.SamplingRate <= 0 {
+		return InvalidParameter{"Sampling rate must be positive"}
+	}
+	if parameters.WindowOverlap < 0 || parameters.WindowOverlap >= 1 {
+		return InvalidParameter{"Window overlap must be in range [0, 1)"}
+	}
+	if parameters.PeriodMin <= 0 || parameters.PeriodMax <= parameters.PeriodMin {
+		return InvalidParameter{"Invalid period range"}
+	}
+	if parameters.DecibelMax <= parameters.DecibelMin || parameters.DecibelStep <= 0 {
+		return InvalidParameter{"Invalid decibel range"}
+	}
+	if parameters.windowLength() < 2 * PPSD_SEGMENTS_RATIO {
+		return InvalidParameter{"Window is too short for sampling rate"}
+	}
+	return nil
+}
+
+func (parameters PPSDParameters) windowLength() int {
+	return int(math.Round(parameters.WindowSeconds * parameters.SamplingRate))
+}
+
+func (parameters PPSDParameters) windowStep() time.Duration {
+	return time.Duration(parameters.WindowSeconds * (1 - parameters.WindowOverlap) * float64(time.Second))
+}
+
+
+type PPSD struct {
+	Parameters PPSDParameters
+	Periods []float64
+	Decibels []float64
+	Histogram [][]uint64
+	WindowStarts []time.Time
+}
+
+
+func NewPPSD(parameters PPSDParameters) (*PPSD, error) {
+	parameters = parameters.withDefaults()
+	if err := parameters.check(); err != nil {
+		return nil, err
+	}
+
+	periods := []float64{}
+	for period := parameters.PeriodMin; period <= parameters.PeriodMax; period *= math.Pow(2, PPSD_PERIOD_STEP_OCTAVES) {
+		periods = append(periods, period)
+	}
+
+	decibelsCount := int(math.Ceil((parameters.DecibelMax - parameters.DecibelMin) / parameters.DecibelStep))
+	decibels := make([]float64, decibelsCount)
+	for i := range decibels {
+		decibels[i] = parameters.DecibelMin + float64(i) * parameters.DecibelStep
+	}
+
+	histogram := make([][]uint64, len(periods))
+	for i := range histogram {
+		histogram[i] = make([]uint64, decibelsCount)
+	}
+
+	return &PPSD{
+		Parameters: parameters,
+		Periods: periods,
+		Decibels: decibels,
+		Histogram: histogram,
+		WindowStarts: []time.Time{}}, nil
+}
+
+func (ppsd *PPSD) WindowsCount() int {
+	return len(ppsd.WindowStarts)
+}
+
+func (ppsd *PPSD) hasWindow(windowStart time.Time) bool {
+	index := sort.Search(len(ppsd.WindowStarts), func(i int) bool {
+		return !ppsd.WindowStarts[i].Before(windowStart)
+	})
+	return index < len(ppsd.WindowStarts) && ppsd.WindowStarts[index].Equal(windowStart)
+}
+
+func (ppsd *PPSD) addWindowStart(windowStart time.Time) {
+	index := sort.Search(len(ppsd.WindowStarts), func(i int) bool {
+		return !ppsd.WindowStarts[i].Before(windowStart)
+	})
+	ppsd.WindowStarts = append(ppsd.WindowStarts, time.Time{})
+	copy(ppsd.WindowStarts[index + 1:], ppsd.WindowStarts[index:])
+	ppsd.WindowStarts[index] = windowStart
+}
+
+func (ppsd *PPSD) bandValue(psd PSD, period float64) float64 {
+	halfBand := math.Pow(2, float64(PPSD_PERIOD_BAND_OCTAVES) / 2)
+	frequencyLow, frequencyHigh := 1 / (period * halfBand), halfBand / period
+
+	var sum float64
+	var count int
+	for i, frequency := range psd.Frequencies {
+		if frequency >= frequencyLow && frequency <= frequencyHigh {
+			sum += psd.Values[i]
+			count++
+		}
+	}
+
+	if count == 0 {
+		return math.NaN()
+	}
+	return Decibel(sum / float64(count))
+}
+
+func (ppsd *PPSD) decibelIndex(value float64) int {
+	if math.IsNaN(value) || value < ppsd.Parameters.DecibelMin || value >= ppsd.Parameters.DecibelMax {
+		return -1
+	}
+	return int((value - ppsd.Parameters.DecibelMin) / ppsd.Parameters.DecibelStep)
+}
+
+func (ppsd *PPSD) AddWindow(signal []float64, windowStart time.Time) (bool, error) {
+	if len(signal) != ppsd.Parameters.windowLength() {
+		return false, BadSignalData{"Window length does not match PPSD window"}
+	}
+
+	windowStart = windowStart.UTC()
+	if ppsd.hasWindow(windowStart) {
+		return false, nil
+	}
+
+	psd, err := WelchPSD(signal, ppsd.Parameters.SamplingRate, WelchParameters{
+		SegmentLength: len(signal) / PPSD_SEGMENTS_RATIO,
+		Overlap: PPSD_SEGMENT_OVERLAP,
+		Window: HANN_WINDOW,
+		CountsPerUnit: ppsd.Parameters.CountsPerUnit})
+	if err != nil {
+		return false, err
+	}
+
+	if ppsd.Parameters.IsVelocity {
+		psd = psd.VelocityToAcceleration()
+	}
+
+	for i, period := range ppsd.Periods {
+		if index := ppsd.decibelIndex(ppsd.bandValue(psd, period)); index >= 0 {
+			ppsd.Histogram[i][index]++
+		}
+	}
+	ppsd.addWindowStart(windowStart)
+	return true, nil
+}
+
+func (ppsd *PPSD) alignedWindowStart(datetime time.Time) time.Time {
+	step := ppsd.Parameters.windowStep()
+	windowStart := datetime.UTC().Truncate(step)
+	if windowStart.Before(datetime) {
+		windowStart = windowStart.Add(step)
+	}
+	return windowStart
+}
+
+func (ppsd *PPSD) AddSignal(signal []int32, datetimeStart time.Time) (int, error) {
+	samplingRate := ppsd.Parameters.SamplingRate
+	windowLength := ppsd.Parameters.windowLength()
+	step := ppsd.Parameters.windowStep()
+
+	addedCount := 0
+	for windowStart := ppsd.alignedWindowStart(datetimeStart); ; windowStart = windowStart.Add(step) {
+		startIndex := int(math.Round(windowStart.Sub(datetimeStart).Seconds() * samplingRate))
+		if startIndex + windowLength > len(signal) {
+			break
+		}
+
+		isAdded, err := ppsd.AddWindow(ToFloat(signal[startIndex:startIndex + windowLength]), windowStart)
+		if err != nil {
+			return addedCount, err
+		}
+		if isAdded {
+			addedCount++
+		}
+	}
+	return addedCount, nil
+}
+
+func (ppsd *PPSD) AddBinaryFile(binFile binaryfile.BinaryFile, component string) (int, error) {
+	recording, err := binFile.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer recording.Close()
+
+	frequency, err := recording.GetResampleFrequency()
+	if err != nil {
+		return 0, err
+	}
+	if float64(frequency) != ppsd.Parameters.SamplingRate {
+		return 0, InvalidParameter{"Recording frequency does not match PPSD sampling rate"}
+	}
+
+	datetimeStop, err := recording.DatetimeStop()
+	if err != nil {
+		return 0, err
+	}
+
+	windowDuration := time.Duration(ppsd.Parameters.WindowSeconds * float64(time.Second))
+	step := ppsd.Parameters.windowStep()
+	addedCount := 0
+	for windowStart := ppsd.alignedWindowStart(recording.DatetimeStart()); !windowStart.Add(windowDuration).After(datetimeStop); windowStart = windowStart.Add(step) {
+		if ppsd.hasWindow(windowStart) {
+			continue
+		}
+
+		signal, err := recording.ReadChannelByName(windowStart, windowStart.Add(windowDuration), component)
+		if err != nil {
+			return addedCount, err
+		}
+		if len(signal) != ppsd.Parameters.windowLength() {
+			continue
+		}
+
+		isAdded, err := ppsd.AddWindow(ToFloat(signal), windowStart)
+		if err != nil {
+			return addedCount, err
+		}
+		if isAdded {
+			addedCount++
+		}
+	}
+	return addedCount, nil
+}
+
+func (ppsd *PPSD) Merge(other *PPSD) error {
+	if ppsd.Parameters != other.Parameters {
+		return InvalidParameter{"Cannot merge PPSDs with different parameters"}
+	}
+
+	for _, windowStart := range other.WindowStarts {
+		if ppsd.hasWindow(windowStart) {
+			return InvalidParameter{"PPSDs contain the same window " + windowStart.Format(time.RFC3339)}
+		}
+	}
+
+	for i := range ppsd.Histogram {
+		for j := range ppsd.Histogram[i] {
+			ppsd.Histogram[i][j] += other.Histogram[i][j]
+		}
+	}
+	for _, windowStart := range other.WindowStarts {
+		ppsd.addWindowStart(windowStart)
+	}
+	return nil
+}
+
+func (ppsd *PPSD) decibelCenter(index int) float64 {
+	return ppsd.Decibels[index] + ppsd.Parameters.DecibelStep / 2
+}
+
+func (ppsd *PPSD) Mode() [][]float64 {
+	result := [][]float64{}
+	for i, counts := range ppsd.Histogram {
+		bestIndex := -1
+		for j, count := range counts {
+			if count > 0 && (bestIndex < 0 || count > counts[bestIndex]) {
+				bestIndex = j
+			}
+		}
+		if bestIndex >= 0 {
+			result = append(result, []float64{ppsd.Periods[i], ppsd.decibelCenter(bestIndex)})
+		}
+	}
+	return result
+}
+
+func (ppsd *PPSD) Mean() [][]float64 {
+	result := [][]float64{}
+	for i, counts := range ppsd.Histogram {
+		var sum float64
+		var total uint64
+		for j, count := range counts {
+			sum += float64(count) * ppsd.decibelCenter(j)
+			total += count
+		}
+		if total > 0 {
+			result = append(result, []float64{ppsd.Periods[i], sum / float64(total)})
+		}
+	}
+	return result
+}
+
+func (ppsd *PPSD) Percentile(percent float64) ([][]float64, error) {
+	if percent < 0 || percent > 100 {
+		return [][]float64{}, InvalidParameter{"Percentile must be in range [0, 100]"}
+	}
+
+	result := [][]float64{}
+	for i, counts := range ppsd.Histogram {
+		var total uint64
+		for _, count := range counts {
+			total += count
+		}
+		if total == 0 {
+			continue
+		}
+
+		threshold := percent / 100 * float64(total)
+		var cumulative uint64
+		for j, count := range counts {
+			cumulative += count
+			if count > 0 && float64(cumulative) >= threshold {
+				result = append(result, []float64{ppsd.Periods[i], ppsd.decibelCenter(j)})
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+
+type ppsdParametersJSON struct {
+	SamplingRate float64 `json:"sampling_rate"`
+	WindowSeconds float64 `json:"window_seconds"`
+	WindowOverlap float64 `json:"window_overlap"`
+	CountsPerUnit float64 `json:"counts_per_unit"`
+	IsVelocity bool `json:"is_velocity"`
+	PeriodMin float64 `json:"period_min"`
+	PeriodMax float64 `json:"period_max"`
+	DecibelMin float64 `json:"decibel_min"`
+	DecibelMax float64 `json:"decibel_max"`
+	DecibelStep float64 `json:"decibel_step"`
+}
+
+
+type ppsdJSON struct {
+	Parameters ppsdParametersJSON `json:"parameters"`
+	Periods []float64 `json:"periods"`
+	Decibels []float64 `json:"decibels"`
+	Histogram [][]uint64 `json:"histogram"`
+	WindowStarts []string `json:"window_starts"`
+}
+
+
+func (ppsd *PPSD) WriteJSON(writer io.Writer) error {
+	parameters := ppsd.Parameters
+	result := ppsdJSON{
+		Parameters: ppsdParametersJSON{
+			SamplingRate: parameters.SamplingRate,
+			WindowSeconds: parameters.WindowSeconds,
+			WindowOverlap: parameters.WindowOverlap,
+			CountsPerUnit: parameters.CountsPerUnit,
+			IsVelocity: parameters.IsVelocity,
+			PeriodMin: parameters.PeriodMin,
+			PeriodMax: parameters.PeriodMax,
+			DecibelMin: parameters.DecibelMin,
+			DecibelMax: parameters.DecibelMax,
+			DecibelStep: parameters.DecibelStep},
+		Periods: ppsd.Periods,
+		Decibels: ppsd.Decibels,
+		Histogram: ppsd.Histogram,
+		WindowStarts: make([]string, len(ppsd.WindowStarts))}
+	for i, windowStart := range ppsd.WindowStarts {
+		result.WindowStarts[i] = windowStart.UTC().Format(time.RFC3339Nano)
+	}
+	return json.NewEncoder(writer).Encode(result)
+}
+
+
+func ReadPPSD(reader io.Reader) (*PPSD, error) {
+	var data ppsdJSON
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	ppsd, err := NewPPSD(PPSDParameters{
+		SamplingRate: data.Parameters.SamplingRate,
+		WindowSeconds: data.Parameters.WindowSeconds,
+		WindowOverlap: data.Parameters.WindowOverlap,
+		CountsPerUnit: data.Parameters.CountsPerUnit,
+		IsVelocity: data.Parameters.IsVelocity,
+		PeriodMin: data.Parameters.PeriodMin,
+		PeriodMax: data.Parameters.PeriodMax,
+		DecibelMin: data.Parameters.DecibelMin,
+		DecibelMax: data.Parameters.DecibelMax,
+		DecibelStep: data.Parameters.DecibelStep})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data.Histogram) != len(ppsd.Periods) {
+		return nil, BadSignalData{"PPSD histogram does not match period bins"}
+	}
+	for i, counts := range data.Histogram {
+		if len(counts) != len(ppsd.Decibels) {
+			return nil, BadSignalData{"PPSD histogram does not match decibel bins"}
+		}
+		copy(ppsd.Histogram[i], counts)
+	}
+
+	for _, value := range data.WindowStarts {
+		windowStart, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, err
+		}
+		ppsd.addWindowStart(windowStart)
+	}
+	return ppsd, nil
+}
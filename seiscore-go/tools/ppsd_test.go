@@ -0,0 +1,175 @@
+package tools
+
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+
+func testNoise(samplesCount int) []int32 {
+	random := rand.New(rand.NewSource(1))
+	signal := make([]int32, samplesCount)
+	for i := range signal {
+		signal[i] = int32(random.NormFloat64() * 1000)
+	}
+	return signal
+}
+
+
+func TestPPSDWindowOverlap(t *testing.T) {
+	start := time.Date(2022, 1, 20, 0, 0, 0, 0, time.UTC)
+	signal := testNoise(4 * 600 * 10)
+	cases := []struct {
+		overlap float64
+		expectedOverlap float64
+		expectedWindowsCount int
+	}{
+		{0, 0, 4},
+		{PPSD_DEFAULT_WINDOW_OVERLAP, PPSD_WINDOW_OVERLAP, 7},
+		{0.75, 0.75, 13},
+	}
+
+	for _, testCase := range cases {
+		ppsd, err := NewPPSD(PPSDParameters{SamplingRate: 10, WindowSeconds: 600, WindowOverlap: testCase.overlap})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ppsd.Parameters.WindowOverlap != testCase.expectedOverlap {
+			t.Errorf("Overlap %g, want %g", ppsd.Parameters.WindowOverlap, testCase.expectedOverlap)
+		}
+
+		windowsCount, err := ppsd.AddSignal(signal, start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if windowsCount != testCase.expectedWindowsCount {
+			t.Errorf("Overlap %g: windows count %d, want %d", testCase.overlap, windowsCount, testCase.expectedWindowsCount)
+		}
+
+		buffer := &bytes.Buffer{}
+		if err := ppsd.WriteJSON(buffer); err != nil {
+			t.Fatal(err)
+		}
+		restored, err := ReadPPSD(buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if restored.Parameters != ppsd.Parameters {
+			t.Errorf("Restored parameters %+v, want %+v", restored.Parameters, ppsd.Parameters)
+		}
+	}
+}
+
+
+func TestPPSDNegativeOverlap(t *testing.T) {
+	_, err := NewPPSD(PPSDParameters{SamplingRate: 10, WindowSeconds: 600, WindowOverlap: -0.5})
+	if err == nil {
+		t.Error("Negative overlap other than default sentinel is accepted")
+	}
+}
+
+
+func TestPPSDStatistics(t *testing.T) {
+	ppsd, err := NewPPSD(PPSDParameters{SamplingRate: 10, WindowSeconds: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ppsd.Histogram[0][10], ppsd.Histogram[0][20], ppsd.Histogram[0][40] = 1, 3, 1
+	period := ppsd.Periods[0]
+
+	checkCurve := func(name string, curve [][]float64, expected float64) {
+		t.Helper()
+		if len(curve) != 1 || curve[0][0] != period || curve[0][1] != expected {
+			t.Errorf("%s is %v, want [[%g %g]]", name, curve, period, expected)
+		}
+	}
+	checkCurve("Mode", ppsd.Mode(), -179.5)
+	checkCurve("Mean", ppsd.Mean(), -177.5)
+
+	percentiles := []struct {
+		percent float64
+		expected float64
+	}{
+		{0, -189.5},
+		{20, -189.5},
+		{50, -179.5},
+		{80, -179.5},
+		{81, -159.5},
+		{100, -159.5},
+	}
+	for _, percentile := range percentiles {
+		curve, err := ppsd.Percentile(percentile.percent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkCurve("Percentile", curve, percentile.expected)
+	}
+
+	if _, err := ppsd.Percentile(101); err == nil {
+		t.Error("Percentile above 100 is accepted")
+	}
+	if _, err := ppsd.Percentile(-1); err == nil {
+		t.Error("Negative percentile is accepted")
+	}
+}
+
+
+func TestPPSDMerge(t *testing.T) {
+	parameters := PPSDParameters{SamplingRate: 10, WindowSeconds: 600, WindowOverlap: 0, CountsPerUnit: 1e9}
+	start := time.Date(2022, 1, 20, 0, 0, 0, 0, time.UTC)
+	signal := testNoise(2 * 600 * 10)
+
+	first, err := NewPPSD(parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.AddSignal(signal, start.Add(20 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := NewPPSD(parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.AddSignal(signal, start); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.Merge(second); err != nil {
+		t.Fatal(err)
+	}
+	if first.WindowsCount() != 4 {
+		t.Fatalf("Merged windows count %d, want 4", first.WindowsCount())
+	}
+	for i, windowStart := range first.WindowStarts {
+		if expected := start.Add(time.Duration(i) * 10 * time.Minute); !windowStart.Equal(expected) {
+			t.Errorf("Window %d starts at %s, want %s", i, windowStart, expected)
+		}
+	}
+
+	var total uint64
+	for _, count := range first.Histogram[len(first.Periods) / 2] {
+		total += count
+	}
+	if total != 4 {
+		t.Errorf("Merged histogram row has %d values, want 4", total)
+	}
+
+	if err := first.Merge(second); err == nil {
+		t.Error("Merge with duplicate windows is accepted")
+	}
+	if first.WindowsCount() != 4 {
+		t.Errorf("Rejected merge changed windows count to %d", first.WindowsCount())
+	}
+
+	other, err := NewPPSD(PPSDParameters{SamplingRate: 10, WindowSeconds: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Merge(other); err == nil {
+		t.Error("Merge with different parameters is accepted")
+	}
+}
@@ -0,0 +1,178 @@
+package tools
+
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+
+const (
+	DEFAULT_WELCH_OVERLAP = 0.5
+)
+
+
+type WelchParameters struct {
+	SegmentLength int
+	Overlap float64
+	Window string
+	TukeyAlpha float64
+	IsZeroPadding bool
+	CountsPerUnit float64
+}
+
+
+type PSD struct {
+	Frequencies []float64
+	Values []float64
+	SegmentsCount int
+}
+
+func (psd PSD) Decibels() [][]float64 {
+	result := make([][]float64, len(psd.Frequencies))
+	for i := range psd.Frequencies {
+		result[i] = []float64{psd.Frequencies[i], Decibel(psd.Values[i])}
+	}
+	return result
+}
+
+func (psd PSD) Periods() [][]float64 {
+	result := [][]float64{}
+	for i := len(psd.Frequencies) - 1; i >= 0; i-- {
+		if psd.Frequencies[i] <= 0 {
+			continue
+		}
+		result = append(result, []float64{1 / psd.Frequencies[i], Decibel(psd.Values[i])})
+	}
+	return result
+}
+
+func (psd PSD) VelocityToAcceleration() PSD {
+	values := make([]float64, len(psd.Values))
+	for i, value := range psd.Values {
+		angularFrequency := 2 * math.Pi * psd.Frequencies[i]
+		values[i] = value * angularFrequency * angularFrequency
+	}
+	return PSD{Frequencies: psd.Frequencies, Values: values, SegmentsCount: psd.SegmentsCount}
+}
+
+
+func Decibel(value float64) float64 {
+	if value <= 0 {
+		return math.Inf(-1)
+	}
+	return 10 * math.Log10(value)
+}
+
+
+func detrend(segment []float64) []float64 {
+	count := float64(len(segment))
+	var sumX, sumY, sumXX, sumXY float64
+	for i, value := range segment {
+		x := float64(i)
+		sumX += x
+		sumY += value
+		sumXX += x * x
+		sumXY += x * value
+	}
+
+	denominator := count * sumXX - sumX * sumX
+	slope := 0.0
+	if denominator != 0 {
+		slope = (count * sumXY - sumX * sumY) / denominator
+	}
+	intercept := (sumY - slope * sumX) / count
+
+	result := make([]float64, len(segment))
+	for i, value := range segment {
+		result[i] = value - intercept - slope * float64(i)
+	}
+	return result
+}
+
+
+func (parameters WelchParameters) check(signalLength int, samplingRate float64) error {
+	if samplingRate <= 0 {
+		return InvalidParameter{"Sampling rate must be positive"}
+	}
+
+	if parameters.SegmentLength < 2 {
+		return InvalidParameter{"Segment length must be at least 2 discretes"}
+	}
+
+	if parameters.Overlap < 0 || parameters.Overlap >= 1 {
+		return InvalidParameter{"Overlap must be in range [0, 1)"}
+	}
+
+	if parameters.CountsPerUnit < 0 {
+		return InvalidParameter{"Counts per unit must not be negative"}
+	}
+
+	if signalLength < parameters.SegmentLength {
+		return BadSignalData{"Short signal - length less than segment length"}
+	}
+	return nil
+}
+
+func (parameters WelchParameters) step() int {
+	step := int(math.Round(float64(parameters.SegmentLength) * (1 - parameters.Overlap)))
+	if step < 1 {
+		return 1
+	}
+	return step
+}
+
+
+func WelchPSD(signal []float64, samplingRate float64, parameters WelchParameters) (PSD, error) {
+	if err := parameters.check(len(signal), samplingRate); err != nil {
+		return PSD{}, err
+	}
+
+	weights, err := WindowWeights(parameters.Window, parameters.TukeyAlpha, parameters.SegmentLength)
+	if err != nil {
+		return PSD{}, err
+	}
+
+	weightsPower := 0.0
+	for _, weight := range weights {
+		weightsPower += weight * weight
+	}
+
+	unitScale := 1.0
+	if parameters.CountsPerUnit > 0 {
+		unitScale = 1 / (parameters.CountsPerUnit * parameters.CountsPerUnit)
+	}
+
+	length := fftLength(parameters.SegmentLength, parameters.IsZeroPadding)
+	psd := PSD{
+		Frequencies: make([]float64, length / 2 + 1),
+		Values: make([]float64, length / 2 + 1)}
+	for i := range psd.Frequencies {
+		psd.Frequencies[i] = float64(i) * samplingRate / float64(length)
+	}
+
+	for start := 0; start + parameters.SegmentLength <= len(signal); start += parameters.step() {
+		segment := detrend(signal[start:start + parameters.SegmentLength])
+		coefficients := windowedCoefficients(segment, weights, length)
+		for i, coefficient := range coefficients {
+			magnitude := cmplx.Abs(coefficient)
+			psd.Values[i] += oneSidedScale(i, length) * magnitude * magnitude
+		}
+		psd.SegmentsCount++
+	}
+
+	normingCoeff := unitScale / (samplingRate * weightsPower * float64(psd.SegmentsCount))
+	for i := range psd.Values {
+		psd.Values[i] *= normingCoeff
+	}
+	return psd, nil
+}
+
+
+func GetWelchPSD(signal []int32, samplingRate float64, parameters WelchParameters) ([][]float64, error) {
+	psd, err := WelchPSD(ToFloat(signal), samplingRate, parameters)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return psd.Decibels(), nil
+}
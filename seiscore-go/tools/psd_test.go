@@ -0,0 +1,69 @@
+package tools
+
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+
+func whiteNoise(samplesCount int, sigma float64) []float64 {
+	random := rand.New(rand.NewSource(2))
+	signal := make([]float64, samplesCount)
+	for i := range signal {
+		signal[i] = sigma * random.NormFloat64()
+	}
+	return signal
+}
+
+
+func TestWelchWhiteNoiseLevel(t *testing.T) {
+	sigma, samplingRate := 2.0, 100.0
+	signal := whiteNoise(200000, sigma)
+	expected := sigma * sigma / (samplingRate / 2)
+
+	for _, windowType := range []string{RECTANGULAR_WINDOW, HANN_WINDOW} {
+		psd, err := WelchPSD(signal, samplingRate, WelchParameters{SegmentLength: 1024, Overlap: 0.5, Window: windowType})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if psd.SegmentsCount != 389 {
+			t.Errorf("%s window: %d segments, want 389", windowType, psd.SegmentsCount)
+		}
+
+		mean := 0.0
+		for _, value := range psd.Values[1:len(psd.Values) - 1] {
+			mean += value
+		}
+		mean /= float64(len(psd.Values) - 2)
+		if math.Abs(mean - expected) > 0.02 * expected {
+			t.Errorf("%s window: mean PSD %g, want %g", windowType, mean, expected)
+		}
+	}
+
+	psd, err := WelchPSD(signal, samplingRate, WelchParameters{SegmentLength: 1024, Window: HANN_WINDOW, CountsPerUnit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := psd.Values[256]; math.Abs(value - expected / 100) > 0.5 * expected / 100 {
+		t.Errorf("PSD in units is %g, want about %g", value, expected / 100)
+	}
+}
+
+
+func TestWelchParametersCheck(t *testing.T) {
+	signal := whiteNoise(1000, 1)
+	cases := []WelchParameters{
+		{SegmentLength: 1},
+		{SegmentLength: 100, Overlap: 1},
+		{SegmentLength: 100, Overlap: -0.5},
+		{SegmentLength: 100, CountsPerUnit: -1},
+		{SegmentLength: 2000},
+	}
+	for _, parameters := range cases {
+		if _, err := WelchPSD(signal, 100, parameters); err == nil {
+			t.Errorf("Parameters %+v are accepted", parameters)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package tools
+
+
+import (
+	"math"
+	"sort"
+)
+
+
+const (
+	KONNO_OHMACHI_CUTOFF = 2 * math.Pi
+	PARZEN_BANDWIDTH_COEFF = 280.0 / 151
+	PARZEN_CUTOFF = 4
+)
+
+
+type SmoothingKernel struct {
+	inputLength int
+	centers []float64
+	starts []int
+	weights [][]float64
+}
+
+func (kernel SmoothingKernel) Centers() []float64 {
+	return kernel.centers
+}
+
+func (kernel SmoothingKernel) ApplyTo(result []float64, values []float64) error {
+	if len(values) != kernel.inputLength {
+		return BadSignalData{"Spectrum length does not match smoothing kernel"}
+	}
+	if len(result) != len(kernel.centers) {
+		return BadSignalData{"Result length does not match smoothing kernel"}
+	}
+
+	for i, weights := range kernel.weights {
+		sum := 0.0
+		for j, value := range values[kernel.starts[i]:kernel.starts[i] + len(weights)] {
+			sum += weights[j] * value
+		}
+		result[i] = sum
+	}
+	return nil
+}
+
+func (kernel SmoothingKernel) Apply(values []float64) ([]float64, error) {
+	result := make([]float64, len(kernel.centers))
+	if err := kernel.ApplyTo(result, values); err != nil {
+		return []float64{}, err
+	}
+	return result, nil
+}
+
+func (kernel SmoothingKernel) Smooth(spectrum [][]float64) ([][]float64, error) {
+	values := make([]float64, len(spectrum))
+	for i, point := range spectrum {
+		values[i] = point[1]
+	}
+
+	smoothed, err := kernel.Apply(values)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return pairs(kernel.centers, smoothed), nil
+}
+
+
+func newSmoothingKernel(frequencies []float64, centers []float64, support func(float64) (float64, float64), weight func(float64, float64) float64) (SmoothingKernel, error) {
+	if !sort.Float64sAreSorted(frequencies) {
+		return SmoothingKernel{}, InvalidParameter{"Spectrum frequencies must be sorted"}
+	}
+
+	if centers == nil {
+		centers = frequencies
+	}
+
+	kernel := SmoothingKernel{
+		inputLength: len(frequencies),
+		centers: centers,
+		starts: make([]int, len(centers)),
+		weights: make([][]float64, len(centers))}
+	for i, center := range centers {
+		low, high := support(center)
+		start := sort.SearchFloat64s(frequencies, low)
+		stop := start
+		for stop < len(frequencies) && frequencies[stop] <= high {
+			stop++
+		}
+
+		weights := make([]float64, stop - start)
+		weightsSum := 0.0
+		for j := range weights {
+			weights[j] = weight(frequencies[start + j], center)
+			weightsSum += weights[j]
+		}
+		if weightsSum > 0 {
+			for j := range weights {
+				weights[j] /= weightsSum
+			}
+		}
+
+		kernel.starts[i] = start
+		kernel.weights[i] = weights
+	}
+	return kernel, nil
+}
+
+
+func KonnoOhmachiWeight(frequency float64, centerFrequency float64, bandwidth float64) float64 {
+	if frequency == centerFrequency {
+		return 1
+	}
+	if frequency <= 0 || centerFrequency <= 0 {
+		return 0
+	}
+
+	value := bandwidth * math.Log10(frequency / centerFrequency)
+	return math.Pow(math.Sin(value) / value, 4)
+}
+
+
+func NewKonnoOhmachiKernel(frequencies []float64, centers []float64, bandwidth float64) (SmoothingKernel, error) {
+	if bandwidth <= 0 {
+		return SmoothingKernel{}, InvalidParameter{"Konno-Ohmachi bandwidth must be positive"}
+	}
+
+	ratio := math.Pow(10, KONNO_OHMACHI_CUTOFF / bandwidth)
+	support := func(center float64) (float64, float64) {
+		return center / ratio, center * ratio
+	}
+	weight := func(frequency float64, center float64) float64 {
+		return KonnoOhmachiWeight(frequency, center, bandwidth)
+	}
+	return newSmoothingKernel(frequencies, centers, support, weight)
+}
+
+
+func NewMovingAverageKernel(frequencies []float64, centers []float64, width float64) (SmoothingKernel, error) {
+	if width <= 0 {
+		return SmoothingKernel{}, InvalidParameter{"Moving average width must be positive"}
+	}
+
+	support := func(center float64) (float64, float64) {
+		return center - width / 2, center + width / 2
+	}
+	weight := func(frequency float64, center float64) float64 {
+		return 1
+	}
+	return newSmoothingKernel(frequencies, centers, support, weight)
+}
+
+
+func NewLogMovingAverageKernel(frequencies []float64, centers []float64, octaves float64) (SmoothingKernel, error) {
+	if octaves <= 0 {
+		return SmoothingKernel{}, InvalidParameter{"Moving average width in octaves must be positive"}
+	}
+
+	ratio := math.Pow(2, octaves / 2)
+	support := func(center float64) (float64, float64) {
+		return center / ratio, center * ratio
+	}
+	weight := func(frequency float64, center float64) float64 {
+		return 1
+	}
+	return newSmoothingKernel(frequencies, centers, support, weight)
+}
+
+
+func ParzenWeight(frequency float64, centerFrequency float64, bandwidth float64) float64 {
+	value := math.Pi * PARZEN_BANDWIDTH_COEFF / bandwidth * (frequency - centerFrequency) / 2
+	if value == 0 {
+		return 1
+	}
+	return math.Pow(math.Sin(value) / value, 4)
+}
+
+
+func NewParzenKernel(frequencies []float64, centers []float64, bandwidth float64) (SmoothingKernel, error) {
+	if bandwidth <= 0 {
+		return SmoothingKernel{}, InvalidParameter{"Parzen bandwidth must be positive"}
+	}
+
+	halfWidth := PARZEN_CUTOFF * bandwidth / PARZEN_BANDWIDTH_COEFF
+	support := func(center float64) (float64, float64) {
+		return center - halfWidth, center + halfWidth
+	}
+	weight := func(frequency float64, center float64) float64 {
+		return ParzenWeight(frequency, center, bandwidth)
+	}
+	return newSmoothingKernel(frequencies, centers, support, weight)
+}
+
+
+func spectrumFrequencies(spectrum [][]float64) []float64 {
+	frequencies := make([]float64, len(spectrum))
+	for i, point := range spectrum {
+		frequencies[i] = point[0]
+	}
+	return frequencies
+}
+
+
+func KonnoOhmachiSmoothing(spectrum [][]float64, bandwidth float64) ([][]float64, error) {
+	kernel, err := NewKonnoOhmachiKernel(spectrumFrequencies(spectrum), nil, bandwidth)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return kernel.Smooth(spectrum)
+}
+
+
+func MovingAverageSmoothing(spectrum [][]float64, width float64) ([][]float64, error) {
+	kernel, err := NewMovingAverageKernel(spectrumFrequencies(spectrum), nil, width)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return kernel.Smooth(spectrum)
+}
+
+
+func LogMovingAverageSmoothing(spectrum [][]float64, octaves float64) ([][]float64, error) {
+	kernel, err := NewLogMovingAverageKernel(spectrumFrequencies(spectrum), nil, octaves)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return kernel.Smooth(spectrum)
+}
+
+
+func ParzenSmoothing(spectrum [][]float64, bandwidth float64) ([][]float64, error) {
+	kernel, err := NewParzenKernel(spectrumFrequencies(spectrum), nil, bandwidth)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return kernel.Smooth(spectrum)
+}
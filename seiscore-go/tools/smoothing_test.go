@@ -0,0 +1,129 @@
+package tools
+
+
+import (
+	"math"
+	"testing"
+)
+
+
+func testFrequencies(count int, step float64) []float64 {
+	frequencies := make([]float64, count)
+	for i := range frequencies {
+		frequencies[i] = float64(i) * step
+	}
+	return frequencies
+}
+
+
+func testKernels(t testing.TB, frequencies []float64, centers []float64) map[string]SmoothingKernel {
+	kernels := map[string]SmoothingKernel{}
+	constructors := map[string]func() (SmoothingKernel, error){
+		"konno-ohmachi": func() (SmoothingKernel, error) { return NewKonnoOhmachiKernel(frequencies, centers, 40) },
+		"moving average": func() (SmoothingKernel, error) { return NewMovingAverageKernel(frequencies, centers, 0.5) },
+		"log moving average": func() (SmoothingKernel, error) { return NewLogMovingAverageKernel(frequencies, centers, 1) },
+		"parzen": func() (SmoothingKernel, error) { return NewParzenKernel(frequencies, centers, 0.5) },
+	}
+	for name, constructor := range constructors {
+		kernel, err := constructor()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		kernels[name] = kernel
+	}
+	return kernels
+}
+
+
+func TestKernelWeightsNormalization(t *testing.T) {
+	frequencies := testFrequencies(2001, 0.01)
+	centers := []float64{0.05, 0.5, 1, 2.5, 10, 19.95}
+	for name, kernel := range testKernels(t, frequencies, centers) {
+		for i, weights := range kernel.weights {
+			if len(weights) == 0 {
+				t.Errorf("%s kernel has no weights at %g Hz", name, centers[i])
+				continue
+			}
+
+			sum := 0.0
+			for _, weight := range weights {
+				if weight < 0 {
+					t.Errorf("%s kernel has negative weight at %g Hz", name, centers[i])
+				}
+				sum += weight
+			}
+			if math.Abs(sum - 1) > 1e-12 {
+				t.Errorf("%s kernel weights at %g Hz sum to %g", name, centers[i], sum)
+			}
+		}
+
+		constant := make([]float64, len(frequencies))
+		for i := range constant {
+			constant[i] = 3
+		}
+		smoothed, err := kernel.Apply(constant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, value := range smoothed {
+			if math.Abs(value - 3) > 1e-12 {
+				t.Errorf("%s kernel smooths constant to %g at %g Hz", name, value, centers[i])
+			}
+		}
+
+		if _, err := kernel.Apply(constant[1:]); err == nil {
+			t.Errorf("%s kernel accepts spectrum of wrong length", name)
+		}
+	}
+}
+
+
+func TestKonnoOhmachiKernelMatchesWeights(t *testing.T) {
+	frequencies := testFrequencies(501, 0.04)
+	spectrum := make([][]float64, len(frequencies))
+	for i, frequency := range frequencies {
+		spectrum[i] = []float64{frequency, 1 + math.Sin(frequency)}
+	}
+
+	smoothed, err := KonnoOhmachiSmoothing(spectrum, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []int{1, 25, 250, 500} {
+		center := frequencies[index]
+		var sum, weightsSum float64
+		for _, point := range spectrum {
+			weight := KonnoOhmachiWeight(point[0], center, 40)
+			if math.Abs(40 * math.Log10(point[0] / center)) > KONNO_OHMACHI_CUTOFF {
+				weight = 0
+			}
+			sum += weight * point[1]
+			weightsSum += weight
+		}
+		if expected := sum / weightsSum; math.Abs(smoothed[index][1] - expected) > 1e-12 {
+			t.Errorf("Smoothed value at %g Hz is %g, want %g", center, smoothed[index][1], expected)
+		}
+	}
+}
+
+
+func BenchmarkKonnoOhmachiKernelApply(b *testing.B) {
+	frequencies := testFrequencies(HVSR_WINDOW_SECONDS * 100 / 2 + 1, 1.0 / HVSR_WINDOW_SECONDS)
+	kernel, err := NewKonnoOhmachiKernel(frequencies, frequencies[1:], HVSR_SMOOTHING_BANDWIDTH)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	spectrum := make([]float64, len(frequencies))
+	for i := range spectrum {
+		spectrum[i] = 1 + math.Sin(frequencies[i])
+	}
+	result := make([]float64, len(kernel.Centers()))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := kernel.ApplyTo(result, spectrum); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package tools
+
+
+import (
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/dsp/window"
+)
+
+
+const (
+	RECTANGULAR_WINDOW, HANN_WINDOW, HAMMING_WINDOW = "rectangular", "hann", "hamming"
+	BLACKMAN_WINDOW, TUKEY_WINDOW = "blackman", "tukey"
+	DEFAULT_TUKEY_ALPHA = 0.5
+)
+
+
+type SpectrumParameters struct {
+	Window string
+	TukeyAlpha float64
+	IsZeroPadding bool
+}
+
+
+type Spectrum struct {
+	Frequencies []float64
+	Amplitudes []float64
+	Powers []float64
+	Phases []float64
+}
+
+func pairs(xs []float64, ys []float64) [][]float64 {
+	result := make([][]float64, len(xs))
+	for i := range xs {
+		result[i] = []float64{xs[i], ys[i]}
+	}
+	return result
+}
+
+func (spectrum Spectrum) Amplitude() [][]float64 {
+	return pairs(spectrum.Frequencies, spectrum.Amplitudes)
+}
+
+func (spectrum Spectrum) Power() [][]float64 {
+	return pairs(spectrum.Frequencies, spectrum.Powers)
+}
+
+func (spectrum Spectrum) Phase() [][]float64 {
+	return pairs(spectrum.Frequencies, spectrum.Phases)
+}
+
+
+func ToFloat(signal []int32) []float64 {
+	result := make([]float64, len(signal))
+	for i, value := range signal {
+		result[i] = float64(value)
+	}
+	return result
+}
+
+
+func NextPowerOfTwo(value int) int {
+	result := 1
+	for result < value {
+		result *= 2
+	}
+	return result
+}
+
+
+func WindowWeights(windowType string, tukeyAlpha float64, length int) ([]float64, error) {
+	weights := make([]float64, length)
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	if length < 2 {
+		return weights, nil
+	}
+
+	switch windowType {
+	case "", RECTANGULAR_WINDOW:
+		return weights, nil
+	case HANN_WINDOW:
+		return window.Hann(weights), nil
+	case HAMMING_WINDOW:
+		return window.Hamming(weights), nil
+	case BLACKMAN_WINDOW:
+		return window.Blackman(weights), nil
+	case TUKEY_WINDOW:
+		if tukeyAlpha == 0 {
+			tukeyAlpha = DEFAULT_TUKEY_ALPHA
+		}
+		if tukeyAlpha < 0 || tukeyAlpha > 1 {
+			return nil, InvalidParameter{"Tukey alpha must be in range [0, 1]"}
+		}
+		return window.Tukey{Alpha: tukeyAlpha}.Transform(weights), nil
+	default:
+		return nil, InvalidParameter{"Unknown window type " + windowType}
+	}
+}
+
+
+func fftLength(signalLength int, isZeroPadding bool) int {
+	if isZeroPadding {
+		return NextPowerOfTwo(signalLength)
+	}
+	return signalLength
+}
+
+
+func windowedCoefficients(signal []float64, weights []float64, length int) []complex128 {
+	sequence := make([]float64, length)
+	for i, value := range signal {
+		sequence[i] = value * weights[i]
+	}
+	return fourier.NewFFT(length).Coefficients(nil, sequence)
+}
+
+
+func oneSidedScale(index int, length int) float64 {
+	if index == 0 || (length % 2 == 0 && index == length / 2) {
+		return 1
+	}
+	return 2
+}
+
+
+func NewSpectrum(signal []float64, samplingRate float64, parameters SpectrumParameters) (Spectrum, error) {
+	if samplingRate <= 0 {
+		return Spectrum{}, InvalidParameter{"Sampling rate must be positive"}
+	}
+
+	if len(signal) < 2 {
+		return Spectrum{}, BadSignalData{"Short signal - length less than 2 discretes"}
+	}
+
+	weights, err := WindowWeights(parameters.Window, parameters.TukeyAlpha, len(signal))
+	if err != nil {
+		return Spectrum{}, err
+	}
+
+	weightsSum := 0.0
+	for _, weight := range weights {
+		weightsSum += weight
+	}
+
+	length := fftLength(len(signal), parameters.IsZeroPadding)
+	coefficients := windowedCoefficients(signal, weights, length)
+
+	spectrum := Spectrum{
+		Frequencies: make([]float64, len(coefficients)),
+		Amplitudes: make([]float64, len(coefficients)),
+		Powers: make([]float64, len(coefficients)),
+		Phases: make([]float64, len(coefficients))}
+	for i, coefficient := range coefficients {
+		scale := oneSidedScale(i, length)
+		magnitude := cmplx.Abs(coefficient) / weightsSum
+
+		spectrum.Frequencies[i] = float64(i) * samplingRate / float64(length)
+		spectrum.Amplitudes[i] = scale * magnitude
+		spectrum.Powers[i] = scale * magnitude * magnitude
+		spectrum.Phases[i] = cmplx.Phase(coefficient)
+	}
+	return spectrum, nil
+}
+
+
+func NewSignalSpectrum(signal []int32, samplingRate float64, parameters SpectrumParameters) (Spectrum, error) {
+	return NewSpectrum(ToFloat(signal), samplingRate, parameters)
+}
+
+
+func GetAmplitudeSpectrum(signal []int32, samplingRate float64, parameters SpectrumParameters) ([][]float64, error) {
+	spectrum, err := NewSignalSpectrum(signal, samplingRate, parameters)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return spectrum.Amplitude(), nil
+}
+
+
+func GetPowerSpectrum(signal []int32, samplingRate float64, parameters SpectrumParameters) ([][]float64, error) {
+	spectrum, err := NewSignalSpectrum(signal, samplingRate, parameters)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return spectrum.Power(), nil
+}
+
+
+func GetPhaseSpectrum(signal []int32, samplingRate float64, parameters SpectrumParameters) ([][]float64, error) {
+	spectrum, err := NewSignalSpectrum(signal, samplingRate, parameters)
+	if err != nil {
+		return [][]float64{}, err
+	}
+	return spectrum.Phase(), nil
+}
@@ -0,0 +1,73 @@
+package tools
+
+
+import (
+	"math"
+	"testing"
+)
+
+
+func testSinusoids(samplesCount int, samplingRate float64) []float64 {
+	signal := make([]float64, samplesCount)
+	for i := range signal {
+		time := float64(i) / samplingRate
+		signal[i] = 5 + 3 * math.Sin(2 * math.Pi * 10 * time) + 2 * math.Cos(math.Pi * samplingRate * time)
+	}
+	return signal
+}
+
+
+func TestSpectrumSinusoidAmplitude(t *testing.T) {
+	signal := testSinusoids(1000, 100)
+	spectrum, err := NewSpectrum(signal, 100, SpectrumParameters{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spectrum.Frequencies) != 501 || spectrum.Frequencies[100] != 10 || spectrum.Frequencies[500] != 50 {
+		t.Fatalf("Unexpected frequencies grid of %d points", len(spectrum.Frequencies))
+	}
+
+	expected := map[int]float64{0: 5, 100: 3, 500: 2}
+	for i, amplitude := range spectrum.Amplitudes {
+		if math.Abs(amplitude - expected[i]) > 1e-9 {
+			t.Errorf("Amplitude at %g Hz is %g, want %g", spectrum.Frequencies[i], amplitude, expected[i])
+		}
+	}
+
+	expectedPowers := map[int]float64{0: 25, 100: 4.5, 500: 4}
+	for i, power := range expectedPowers {
+		if math.Abs(spectrum.Powers[i] - power) > 1e-9 {
+			t.Errorf("Power at %g Hz is %g, want %g", spectrum.Frequencies[i], spectrum.Powers[i], power)
+		}
+	}
+}
+
+
+func TestWindowedSpectrumAmplitude(t *testing.T) {
+	signal := testSinusoids(1000, 100)
+	for _, windowType := range []string{HANN_WINDOW, HAMMING_WINDOW, BLACKMAN_WINDOW, TUKEY_WINDOW} {
+		spectrum, err := NewSpectrum(signal, 100, SpectrumParameters{Window: windowType})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(spectrum.Amplitudes[100] - 3) > 0.01 {
+			t.Errorf("%s window: amplitude at 10 Hz is %g, want 3", windowType, spectrum.Amplitudes[100])
+		}
+	}
+}
+
+
+func TestSpectrumParametersCheck(t *testing.T) {
+	if _, err := NewSpectrum([]float64{1, 2, 3}, 0, SpectrumParameters{}); err == nil {
+		t.Error("Zero sampling rate is accepted")
+	}
+	if _, err := NewSpectrum([]float64{1}, 100, SpectrumParameters{}); err == nil {
+		t.Error("Single sample signal is accepted")
+	}
+	if _, err := NewSpectrum([]float64{1, 2, 3}, 100, SpectrumParameters{Window: "triangle"}); err == nil {
+		t.Error("Unknown window is accepted")
+	}
+	if _, err := NewSpectrum([]float64{1, 2, 3}, 100, SpectrumParameters{Window: TUKEY_WINDOW, TukeyAlpha: 2}); err == nil {
+		t.Error("Tukey alpha out of range is accepted")
+	}
+}